@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+var accountsCmd = &cobra.Command{
+	Use:               "accounts",
+	Short:             "List and inspect the auth files on the management server",
+	PersistentPreRunE: checkOutputFormat,
+}
+
+var accountsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every auth file without fetching quota",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		_, client := mustLoadClient()
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			errorColor.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+
+		if outputFormat == outputJSON {
+			printJSON(accounts)
+			return
+		}
+		printAccountsTable(accounts)
+	},
+}
+
+var accountsShowCmd = &cobra.Command{
+	Use:   "show <email|id>",
+	Short: "Show all known metadata for an account",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		_, client := mustLoadClient()
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			errorColor.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+
+		matched := selectAccounts(accounts, quotasense.Filter{Accounts: args})
+		if len(matched) == 0 {
+			errorColor.Printf("No account matches %q\n", args[0])
+			os.Exit(1)
+		}
+
+		if outputFormat == outputJSON {
+			printJSON(matched)
+			return
+		}
+		for i, a := range matched {
+			if i > 0 {
+				fmt.Println()
+			}
+			printAccountDetails(a)
+		}
+	},
+}
+
+// selectAccounts returns the accounts matching filter, in server order.
+func selectAccounts(accounts []quotasense.Account, filter quotasense.Filter) []quotasense.Account {
+	var matched []quotasense.Account
+	for _, a := range accounts {
+		if filter.Match(a) {
+			matched = append(matched, a)
+		}
+	}
+	return matched
+}
+
+func printAccountsTable(accounts []quotasense.Account) {
+	headerColor.Printf("%-25s | %-35s | %-12s | %-10s | %-20s | %-8s | %s\n", "ID", "Email", "Provider", "Auth Index", "Project ID", "Disabled", "Account")
+	headerColor.Println(strings.Repeat("-", 150))
+	for _, a := range accounts {
+		rowColor := color.New(color.FgWhite)
+		disabled := "no"
+		if a.Disabled {
+			rowColor = color.New(color.FgHiBlack)
+			disabled = "yes"
+		}
+		rowColor.Printf("%-25s | %-35s | %-12s | %-10s | %-20s | %-8s | %s\n",
+			a.ID, a.Email, a.Provider, a.AuthIndex, orDash(a.ProjectID), disabled, orDash(a.Label))
+	}
+}
+
+// modelledKeys are the auth file fields already shown by printAccountDetails.
+var modelledKeys = []string{"id", "name", "email", "type", "disabled", "auth_index", "project_id", "account"}
+
+func printAccountDetails(a quotasense.Account) {
+	headerColor.Println(a.Email)
+	fields := [][2]string{
+		{"ID", a.ID},
+		{"File name", a.FileName()},
+		{"Provider", a.Provider},
+		{"Auth index", a.AuthIndex},
+		{"Project ID", orDash(a.ProjectID)},
+		{"Account", orDash(a.Label)},
+		{"Disabled", fmt.Sprint(a.Disabled)},
+	}
+	if a.ChatGPTAccountID != "" {
+		fields = append(fields, [2]string{"ChatGPT account", a.ChatGPTAccountID})
+	}
+	for _, f := range fields {
+		fmt.Printf("  %-18s %s\n", f[0]+":", f[1])
+	}
+
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(a.Metadata, &extra); err != nil {
+		return
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		if !slices.Contains(modelledKeys, k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Printf("  %-18s %s\n", k+":", formatMetadataValue(extra[k]))
+	}
+}
+
+// formatMetadataValue prints JSON strings without quotes and anything else as
+// compact JSON.
+func formatMetadataValue(raw json.RawMessage) string {
+	var s string
+	if bytes.HasPrefix(raw, []byte(`"`)) && json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	addOutputFlag(accountsCmd)
+	accountsCmd.AddCommand(accountsListCmd, accountsShowCmd)
+	rootCmd.AddCommand(accountsCmd)
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+var uploadName string
+
+// uploadResult is printed with --output json after an add or replace.
+type uploadResult struct {
+	Account *quotasense.Account `json:"account,omitempty"`
+	Limits  []quotasense.Limit  `json:"limits,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// removal is printed with --output json for each removed account.
+type removal struct {
+	Account quotasense.Account `json:"account"`
+	Removed bool               `json:"removed"`
+	Error   string             `json:"error,omitempty"`
+}
+
+var accountsAddCmd = &cobra.Command{
+	Use:   "add <file.json>",
+	Short: "Upload a new auth file and check that its quota can be read",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		data, provider := mustReadAuthFile(args[0])
+		name := uploadName
+		if name == "" {
+			name = filepath.Base(args[0])
+		}
+
+		noCache = true
+		_, client := mustLoadClient()
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			errorColor.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+		for _, a := range accounts {
+			if a.FileName() == name {
+				errorColor.Printf("An auth file named %s already exists (%s); use `qs accounts replace` instead\n", name, a.Email)
+				os.Exit(1)
+			}
+		}
+
+		if err := client.UploadAuthFile(ctx, name, data); err != nil {
+			errorColor.Printf("Error uploading %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		if outputFormat == outputTable {
+			fmt.Printf("  %s Uploaded %s (%s)\n", okMark, name, provider)
+		}
+		if !verifyUpload(ctx, client, name) {
+			os.Exit(1)
+		}
+	},
+}
+
+var accountsReplaceCmd = &cobra.Command{
+	Use:   "replace <email|id> <file.json>",
+	Short: "Replace an account's auth file, e.g. after logging in again",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		data, provider := mustReadAuthFile(args[1])
+
+		noCache = true
+		_, client := mustLoadClient()
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			errorColor.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+		matched := selectAccounts(accounts, quotasense.Filter{Accounts: args[:1]})
+		if len(matched) != 1 {
+			errorColor.Printf("%q matches %d accounts; it must match exactly one\n", args[0], len(matched))
+			os.Exit(1)
+		}
+		target := matched[0]
+		if target.Provider != provider {
+			errorColor.Printf("%s is a %s account but %s is a %s auth file\n", target.ID, target.Provider, args[1], provider)
+			os.Exit(1)
+		}
+
+		if !assumeYes && !confirm(ctx, fmt.Sprintf("Replace %s (%s)?", target.ID, target.Email)) {
+			fmt.Fprintln(os.Stderr, "Cancelled.")
+			return
+		}
+		if err := client.UploadAuthFile(ctx, target.FileName(), data); err != nil {
+			errorColor.Printf("Error uploading %s: %v\n", target.FileName(), err)
+			os.Exit(1)
+		}
+		if outputFormat == outputTable {
+			fmt.Printf("  %s Replaced %s\n", okMark, target.FileName())
+		}
+		if !verifyUpload(ctx, client, target.FileName()) {
+			os.Exit(1)
+		}
+	},
+}
+
+var accountsRemoveCmd = &cobra.Command{
+	Use:   "remove [email-glob|id]...",
+	Short: "Delete auth files from the management server",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		_, client := mustLoadClient()
+		matched := mustSelectAccounts(ctx, client, args)
+
+		if outputFormat == outputTable {
+			fmt.Println("Accounts to remove:")
+			for _, a := range matched {
+				fmt.Printf("  %-40s | %-15s | %s\n", a.Email, a.Provider, a.ID)
+			}
+		}
+		if dryRun {
+			if outputFormat == outputJSON {
+				printJSON(matched)
+			} else {
+				fmt.Println("Dry run: no changes made.")
+			}
+			return
+		}
+		if !assumeYes && !confirm(ctx, fmt.Sprintf("Permanently remove %d auth file(s)?", len(matched))) {
+			fmt.Fprintln(os.Stderr, "Cancelled.")
+			return
+		}
+
+		failed := false
+		removals := make([]removal, len(matched))
+		for i, a := range matched {
+			removals[i] = removal{Account: a}
+			if err := client.DeleteAccount(ctx, a); err != nil {
+				removals[i].Error = err.Error()
+				failed = true
+				if outputFormat == outputTable {
+					fmt.Printf("  %s %s: %v\n", failMark, a.ID, err)
+				}
+				continue
+			}
+			removals[i].Removed = true
+			if outputFormat == outputTable {
+				fmt.Printf("  %s removed %s\n", okMark, a.ID)
+			}
+		}
+		if outputFormat == outputJSON {
+			printJSON(removals)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// mustReadAuthFile reads and validates a local auth file, exiting if it is
+// unusable, and returns its contents and provider type.
+func mustReadAuthFile(path string) ([]byte, string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		errorColor.Printf("Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	provider, err := quotasense.ValidateAuthFile(data)
+	if err != nil {
+		errorColor.Printf("%s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if !slices.Contains(quotasense.Providers(), provider) {
+		fmt.Fprintf(os.Stderr, "%s Quota checks are not supported for %s accounts; the file will be uploaded but not verified.\n", warnMark, provider)
+	}
+	return data, provider
+}
+
+// verifyUpload looks up the uploaded auth file and fetches its quota to
+// confirm the account works. Callers disable the response cache so that a
+// replaced account is not checked against its old entry. It returns false if
+// the account is missing or its quota cannot be read.
+func verifyUpload(ctx context.Context, client *quotasense.Client, name string) bool {
+	var result uploadResult
+	defer func() {
+		if outputFormat == outputJSON {
+			printJSON(result)
+		}
+	}()
+
+	accounts, err := client.ListAccounts(ctx)
+	if err != nil {
+		result.Error = err.Error()
+		if outputFormat == outputTable {
+			fmt.Printf("  %s Could not list accounts: %v\n", failMark, err)
+		}
+		return false
+	}
+	idx := slices.IndexFunc(accounts, func(a quotasense.Account) bool { return a.FileName() == name })
+	if idx < 0 {
+		result.Error = "auth file not found after upload"
+		if outputFormat == outputTable {
+			fmt.Printf("  %s %s does not appear in the server's auth files\n", failMark, name)
+		}
+		return false
+	}
+	account := accounts[idx]
+	result.Account = &account
+
+	if !slices.Contains(quotasense.Providers(), account.Provider) {
+		return true
+	}
+	limits, err := client.Quotas(ctx, account)
+	if err != nil {
+		result.Error = describeFetchErrorDetail(err)
+		if outputFormat == outputTable {
+			fmt.Printf("  %s Quota check failed for %s: %s\n", failMark, account.Email, describeFetchError(err))
+			color.New(color.FgHiBlack).Printf("    ↳ %s\n", describeFetchErrorDetail(err))
+		}
+		return false
+	}
+	result.Limits = limits
+	if outputFormat == outputTable {
+		fmt.Printf("  %s Quota check passed for %s (%d limits)\n", okMark, account.Email, len(limits))
+	}
+	return true
+}
+
+func init() {
+	accountsAddCmd.Flags().StringVar(&uploadName, "name", "", "File name to store the auth file under (default: the local file name)")
+	accountsReplaceCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Skip the confirmation prompt")
+	addSelectionFlags(accountsRemoveCmd)
+	accountsCmd.AddCommand(accountsAddCmd, accountsReplaceCmd, accountsRemoveCmd)
+}
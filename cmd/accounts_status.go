@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selectProviders []string
+	assumeYes       bool
+	dryRun          bool
+)
+
+// statusChange is the outcome of enabling or disabling one account, as
+// printed with --output json.
+type statusChange struct {
+	Account  quotasense.Account `json:"account"`
+	Disabled bool               `json:"disabled"`
+	Applied  bool               `json:"applied"`
+	Error    string             `json:"error,omitempty"`
+}
+
+var accountsEnableCmd = &cobra.Command{
+	Use:   "enable [email-glob|id]...",
+	Short: "Enable accounts on the management server",
+	Run: func(cmd *cobra.Command, args []string) {
+		setAccountsDisabled(args, false)
+	},
+}
+
+var accountsDisableCmd = &cobra.Command{
+	Use:   "disable [email-glob|id]...",
+	Short: "Disable accounts on the management server",
+	Run: func(cmd *cobra.Command, args []string) {
+		setAccountsDisabled(args, true)
+	},
+}
+
+// setAccountsDisabled selects accounts by the given email globs or IDs and
+// --provider, then changes those not already in the wanted state after
+// confirmation.
+func setAccountsDisabled(selectors []string, disabled bool) {
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	_, client := mustLoadClient()
+	matched := mustSelectAccounts(ctx, client, selectors)
+
+	verb := "enable"
+	if disabled {
+		verb = "disable"
+	}
+
+	var changes []statusChange
+	for _, a := range matched {
+		if a.Disabled == disabled {
+			if outputFormat == outputTable {
+				fmt.Printf("  %s %s (%s) is already %sd\n", okMark, a.Email, a.ID, verb)
+			}
+			continue
+		}
+		changes = append(changes, statusChange{Account: a, Disabled: disabled})
+	}
+	if len(changes) == 0 {
+		if outputFormat == outputJSON {
+			printJSON([]statusChange{})
+		}
+		return
+	}
+
+	if outputFormat == outputTable {
+		fmt.Printf("Accounts to %s:\n", verb)
+		for _, c := range changes {
+			fmt.Printf("  %-40s | %-15s | %s\n", c.Account.Email, c.Account.Provider, c.Account.ID)
+		}
+	}
+	if dryRun {
+		if outputFormat == outputJSON {
+			printJSON(changes)
+		} else {
+			fmt.Println("Dry run: no changes made.")
+		}
+		return
+	}
+	if !assumeYes && !confirm(ctx, fmt.Sprintf("%s %d account(s)?", strings.ToUpper(verb[:1])+verb[1:], len(changes))) {
+		fmt.Fprintln(os.Stderr, "Cancelled.")
+		return
+	}
+
+	failed := applyStatusChanges(ctx, client, changes)
+	if outputFormat == outputJSON {
+		printJSON(changes)
+	} else {
+		for _, c := range changes {
+			if c.Applied {
+				fmt.Printf("  %s %sd %s\n", okMark, verb, c.Account.Email)
+			} else {
+				fmt.Printf("  %s %s: %s\n", failMark, c.Account.Email, c.Error)
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// mustSelectAccounts lists the accounts matching the email globs or IDs and
+// --provider, exiting if no selector was given or nothing matches.
+func mustSelectAccounts(ctx context.Context, client *quotasense.Client, selectors []string) []quotasense.Account {
+	if len(selectors) == 0 && len(selectProviders) == 0 {
+		errorColor.Println("Error: give at least one email glob, ID or --provider")
+		os.Exit(1)
+	}
+
+	accounts, err := client.ListAccounts(ctx)
+	if err != nil {
+		errorColor.Printf("Error listing accounts: %v\n", err)
+		os.Exit(1)
+	}
+
+	matched := selectAccounts(accounts, quotasense.Filter{Accounts: selectors, Providers: selectProviders})
+	if len(matched) == 0 {
+		errorColor.Println("No accounts match the selection")
+		os.Exit(1)
+	}
+	return matched
+}
+
+// addSelectionFlags registers the flags shared by commands that change the
+// selected accounts.
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&selectProviders, "provider", "p", nil, "Select every account of this provider (repeatable)")
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which accounts would change without changing them")
+}
+
+// applyStatusChanges sends each change to the server, recording the result
+// in place, and reports whether any failed.
+func applyStatusChanges(ctx context.Context, client *quotasense.Client, changes []statusChange) bool {
+	failed := false
+	for i := range changes {
+		if err := client.SetDisabled(ctx, changes[i].Account, changes[i].Disabled); err != nil {
+			changes[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		changes[i].Applied = true
+		changes[i].Account.Disabled = changes[i].Disabled
+	}
+	return failed
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin.
+// It returns false as soon as ctx is cancelled, so Ctrl-C at the prompt
+// cancels instead of waiting for a line of input.
+func confirm(ctx context.Context, question string) bool {
+	fmt.Fprintf(os.Stderr, "%s (y/n): ", question)
+	answer := make(chan string, 1)
+	go func() {
+		var s string
+		fmt.Scanln(&s)
+		answer <- s
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr)
+		return false
+	case s := <-answer:
+		return strings.ToLower(strings.TrimSpace(s)) == "y"
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{accountsEnableCmd, accountsDisableCmd} {
+		addSelectionFlags(cmd)
+		accountsCmd.AddCommand(cmd)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+func TestSelectAccounts(t *testing.T) {
+	accounts := []quotasense.Account{
+		{ID: "a.json", Email: "alice@example.com", Provider: "codex"},
+		{ID: "b.json", Email: "bob@example.com", Provider: "gemini-cli"},
+		{ID: "c.json", Email: "alice@example.com", Provider: "claude"},
+	}
+	tests := []struct {
+		filter   quotasense.Filter
+		expected []string
+	}{
+		{quotasense.Filter{Accounts: []string{"alice@example.com"}}, []string{"a.json", "c.json"}},
+		{quotasense.Filter{Accounts: []string{"b.json"}}, []string{"b.json"}},
+		{quotasense.Filter{Accounts: []string{"*@example.com"}, Providers: []string{"claude"}}, []string{"c.json"}},
+		{quotasense.Filter{Accounts: []string{"nobody"}}, nil},
+	}
+
+	for _, test := range tests {
+		matched := selectAccounts(accounts, test.filter)
+		if len(matched) != len(test.expected) {
+			t.Errorf("selectAccounts(%+v) = %v; want %v", test.filter, matched, test.expected)
+			continue
+		}
+		for i, a := range matched {
+			if a.ID != test.expected[i] {
+				t.Errorf("selectAccounts(%+v)[%d] = %s; want %s", test.filter, i, a.ID, test.expected[i])
+			}
+		}
+	}
+}
+
+func TestFormatMetadataValue(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{`"active"`, "active"},
+		{`512`, "512"},
+		{`{ "plan_type": "plus" }`, `{"plan_type":"plus"}`},
+		{`null`, "null"},
+	}
+
+	for _, test := range tests {
+		if got := formatMetadataValue(json.RawMessage(test.raw)); got != test.expected {
+			t.Errorf("formatMetadataValue(%s) = %q; want %q", test.raw, got, test.expected)
+		}
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe returned error: %v", err)
+	}
+	defer func(old *os.File) { os.Stdin = old }(os.Stdin)
+	os.Stdin = r
+	defer w.Close()
+
+	fmt.Fprintln(w, "Y")
+	if !confirm(context.Background(), "Go?") {
+		t.Error("confirm = false for answer \"Y\"")
+	}
+
+	// Nothing is typed: cancelling the context must end the prompt.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if confirm(ctx, "Go?") {
+		t.Error("confirm = true after the context was cancelled")
+	}
+}
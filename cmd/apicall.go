@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+var (
+	apiCallAccount string
+	apiCallMethod  string
+	apiCallData    string
+	apiCallHeaders []string
+	apiCallInclude bool
+	apiCallRaw     bool
+)
+
+var apiCallCmd = &cobra.Command{
+	Use:   "api-call --account <email|id> [-X METHOD] [-H 'Key: Value']... [-d DATA] <url>",
+	Short: "Send a raw request upstream with an account's credentials",
+	Long: `Sends a request through the management server's api-call proxy as the
+given account and prints the upstream status and body. The server replaces
+$TOKEN$ in header values with the account's access token; unless you pass
+your own Authorization header, "Authorization: Bearer $TOKEN$" is added.
+
+The status line and headers are written to stderr and the body to stdout,
+pretty-printed if it is JSON.`,
+	Example: `  qs api-call --account me@example.com https://chatgpt.com/backend-api/wham/usage
+  qs api-call -a gemini.json -X POST -d '{"project":"my-project"}' \
+    -H 'Content-Type: application/json' \
+    https://cloudcode-pa.googleapis.com/v1internal:retrieveUserQuota`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		header, err := parseHeaders(apiCallHeaders)
+		if err != nil {
+			errorColor.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		body, err := readRequestData(apiCallData)
+		if err != nil {
+			errorColor.Printf("Error reading request data: %v\n", err)
+			os.Exit(1)
+		}
+		method := strings.ToUpper(apiCallMethod)
+		if method == "" {
+			method = "GET"
+			if body != "" {
+				method = "POST"
+			}
+		}
+
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		_, client := mustLoadClient()
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			errorColor.Printf("Error listing accounts: %v\n", err)
+			os.Exit(1)
+		}
+		matched := selectAccounts(accounts, quotasense.Filter{Accounts: []string{apiCallAccount}})
+		if len(matched) != 1 {
+			errorColor.Printf("%q matches %d accounts; it must match exactly one\n", apiCallAccount, len(matched))
+			for _, a := range matched {
+				fmt.Fprintf(os.Stderr, "  %s (%s, %s)\n", a.ID, a.Email, a.Provider)
+			}
+			os.Exit(1)
+		}
+
+		resp, err := client.APICall(ctx, matched[0], quotasense.APIRequest{
+			Method: method,
+			URL:    args[0],
+			Header: header,
+			Body:   body,
+		})
+		if err != nil {
+			errorColor.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		printProxyResponse(resp)
+	},
+}
+
+// parseHeaders turns "Key: Value" flags into a header map, adding the
+// default Authorization header unless one was given.
+func parseHeaders(raw []string) (map[string]string, error) {
+	header := make(map[string]string)
+	hasAuth := false
+	for _, h := range raw {
+		key, value, ok := strings.Cut(h, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q (want 'Key: Value')", h)
+		}
+		header[key] = strings.TrimSpace(value)
+		if strings.EqualFold(key, "Authorization") {
+			hasAuth = true
+		}
+	}
+	if !hasAuth {
+		header["Authorization"] = "Bearer $TOKEN$"
+	}
+	return header, nil
+}
+
+// readRequestData returns data as is, or the contents of a file for
+// "@path" and of stdin for "@-".
+func readRequestData(data string) (string, error) {
+	path, ok := strings.CutPrefix(data, "@")
+	if !ok {
+		return data, nil
+	}
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	return string(b), err
+}
+
+func printProxyResponse(resp *quotasense.ProxyResponse) {
+	statusColor := successColor
+	if resp.StatusCode >= 400 {
+		statusColor = errorColor
+	}
+	statusColor.Fprintf(os.Stderr, "HTTP %d\n", resp.StatusCode)
+
+	if apiCallInclude {
+		keys := make([]string, 0, len(resp.Header))
+		for k := range resp.Header {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, v := range resp.Header[k] {
+				color.New(color.FgHiBlack).Fprintf(os.Stderr, "%s: %s\n", k, v)
+			}
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+
+	fmt.Println(formatResponseBody(resp.Body, !apiCallRaw))
+}
+
+// formatResponseBody indents JSON bodies when pretty is set and returns
+// anything else unchanged.
+func formatResponseBody(body string, pretty bool) string {
+	if pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(body), "", "  "); err == nil {
+			return buf.String()
+		}
+	}
+	return body
+}
+
+func init() {
+	apiCallCmd.Flags().StringVarP(&apiCallAccount, "account", "a", "", "Account email, email glob or auth file ID to send the request as")
+	apiCallCmd.Flags().StringVarP(&apiCallMethod, "request", "X", "", "HTTP method (default GET, or POST with --data)")
+	apiCallCmd.Flags().StringVarP(&apiCallData, "data", "d", "", "Request body; @file reads it from a file and @- from stdin")
+	apiCallCmd.Flags().StringArrayVarP(&apiCallHeaders, "header", "H", nil, "Request header as 'Key: Value' (repeatable)")
+	apiCallCmd.Flags().BoolVarP(&apiCallInclude, "include", "i", false, "Print the response headers")
+	apiCallCmd.Flags().BoolVar(&apiCallRaw, "raw", false, "Print the body without pretty-printing JSON")
+	apiCallCmd.MarkFlagRequired("account")
+	rootCmd.AddCommand(apiCallCmd)
+}
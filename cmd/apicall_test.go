@@ -0,0 +1,49 @@
+package cmd
+
+import "testing"
+
+func TestParseHeaders(t *testing.T) {
+	header, err := parseHeaders([]string{"Content-Type: application/json", "X-Empty:"})
+	if err != nil {
+		t.Fatalf("parseHeaders returned error: %v", err)
+	}
+	expected := map[string]string{
+		"Content-Type":  "application/json",
+		"X-Empty":       "",
+		"Authorization": "Bearer $TOKEN$",
+	}
+	if len(header) != len(expected) {
+		t.Errorf("parseHeaders = %v; want %v", header, expected)
+	}
+	for k, v := range expected {
+		if header[k] != v {
+			t.Errorf("header %q = %q; want %q", k, header[k], v)
+		}
+	}
+
+	header, err = parseHeaders([]string{"authorization: Basic abc"})
+	if err != nil || len(header) != 1 || header["authorization"] != "Basic abc" {
+		t.Errorf("parseHeaders with custom auth = %v, %v; want only the given header", header, err)
+	}
+
+	if _, err := parseHeaders([]string{"no-colon"}); err == nil {
+		t.Errorf("parseHeaders accepted a header without a colon")
+	}
+}
+
+func TestFormatResponseBody(t *testing.T) {
+	tests := []struct {
+		body     string
+		pretty   bool
+		expected string
+	}{
+		{`{"a":1}`, true, "{\n  \"a\": 1\n}"},
+		{`{"a":1}`, false, `{"a":1}`},
+		{`not json`, true, `not json`},
+	}
+	for _, test := range tests {
+		if got := formatResponseBody(test.body, test.pretty); got != test.expected {
+			t.Errorf("formatResponseBody(%q, %v) = %q; want %q", test.body, test.pretty, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/internal/cache"
+	"github.com/quaywin/quota-sense-cli/internal/config"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+// defaultCacheTTL is how long quota is reused when cache_ttl_seconds is unset.
+const defaultCacheTTL = 60 * time.Second
+
+var (
+	noCache bool
+	maxAge  time.Duration
+)
+
+// newClient creates a quota client from the config file settings and the
+// command-line flags, warning loudly if certificate verification is off.
+func newClient(cfg *config.Config) (*quotasense.Client, error) {
+	opts := []quotasense.Option{
+		quotasense.WithManagementTimeout(time.Duration(cfg.ManagementTimeout) * time.Second),
+		quotasense.WithQuotaTimeout(time.Duration(cfg.QuotaTimeout) * time.Second),
+		quotasense.WithConcurrency(cfg.Concurrency),
+		quotasense.WithProxyURL(cfg.ProxyURL),
+	}
+	if concurrency > 0 {
+		opts = append(opts, quotasense.WithConcurrency(concurrency))
+	}
+	for provider, rp := range cfg.Retry {
+		opts = append(opts, quotasense.WithRetryPolicy(provider, quotasense.RetryPolicy{
+			MaxAttempts:    rp.MaxAttempts,
+			InitialBackoff: time.Duration(rp.InitialBackoffMs) * time.Millisecond,
+			MaxBackoff:     time.Duration(rp.MaxBackoffMs) * time.Millisecond,
+		}))
+	}
+	if cfg.TLS != nil {
+		opts = append(opts, quotasense.WithTLS(quotasense.TLSConfig{
+			CABundle:           cfg.TLS.CABundle,
+			ClientCert:         cfg.TLS.ClientCert,
+			ClientKey:          cfg.TLS.ClientKey,
+			ServerName:         cfg.TLS.ServerName,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		}))
+		if cfg.TLS.InsecureSkipVerify {
+			color.New(color.FgRed, color.Bold).Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (insecure_skip_verify). The management token can be intercepted.")
+		}
+	}
+	opts = append(opts, debugOptions()...)
+	opts = append(opts, cacheOptions(cfg)...)
+
+	return quotasense.New(cfg.ServerURL, cfg.ManagementToken, opts...)
+}
+
+// cacheOptions enables the response cache with the TTL from the config file,
+// overridden by --max-age, unless --no-cache is set.
+func cacheOptions(cfg *config.Config) []quotasense.Option {
+	ttl := defaultCacheTTL
+	switch {
+	case cfg.CacheTTL > 0:
+		ttl = time.Duration(cfg.CacheTTL) * time.Second
+	case cfg.CacheTTL < 0:
+		ttl = 0
+	}
+	if maxAge > 0 {
+		ttl = maxAge
+	}
+	if noCache || ttl <= 0 {
+		return nil
+	}
+
+	dir, err := cache.DefaultDir("responses")
+	if err != nil {
+		return nil
+	}
+	return []quotasense.Option{quotasense.WithResponseCache(dir, ttl)}
+}
+
+// mustLoadClient loads the config file and creates a client, exiting with an
+// error message if either fails.
+func mustLoadClient() (*config.Config, *quotasense.Client) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		errorColor.Printf("Error loading config (run `qs config` first): %v\n", err)
+		os.Exit(1)
+	}
+	client, err := newClient(cfg)
+	if err != nil {
+		errorColor.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg, client
+}
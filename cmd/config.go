@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/quaywin/quota-sense-cli/internal/api"
 	"github.com/quaywin/quota-sense-cli/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -20,9 +19,16 @@ var configCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		client := api.NewClient(cfg)
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		client, err := newClient(cfg)
+		if err != nil {
+			errorColor.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Println("Verifying connection...")
-		if err := client.CheckConnection(); err != nil {
+		if err := client.CheckConnection(ctx); err != nil {
 			errorColor.Printf("Connection failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+var (
+	debugMode   bool
+	debugBodies bool
+	debugUnsafe bool
+)
+
+// debugEnabled reports whether debug logging was requested with --debug,
+// --debug-bodies, --debug-unsafe or a truthy QS_DEBUG environment variable.
+func debugEnabled() bool {
+	if debugMode || debugBodies || debugUnsafe {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("QS_DEBUG"))) {
+	case "", "0", "false", "no", "off":
+		return false
+	}
+	return true
+}
+
+// debugOptions returns the client options for the debug flags.
+func debugOptions() []quotasense.Option {
+	if !debugEnabled() {
+		return nil
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return []quotasense.Option{
+		quotasense.WithLogger(logger),
+		quotasense.WithBodyDump(debugBodies),
+		quotasense.WithUnsafeLogging(debugUnsafe),
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Log every management and proxied request to stderr (or set QS_DEBUG=1)")
+	rootCmd.PersistentFlags().BoolVar(&debugBodies, "debug-bodies", false, "Include redacted request and response bodies in debug logs")
+	rootCmd.PersistentFlags().BoolVar(&debugUnsafe, "debug-unsafe", false, "Disable redaction of tokens, account IDs and emails in debug logs")
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	okMark   = color.New(color.FgGreen).Sprint("✓")
+	warnMark = color.New(color.FgYellow).Sprint("!")
+	failMark = color.New(color.FgRed).Sprint("✗")
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check configuration and connectivity to the management server",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		headerColor.Println("Configuration")
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("  %s Config file %s: %v\n", failMark, config.GetConfigPath(), err)
+			os.Exit(1)
+		}
+		fmt.Printf("  %s Config file %s\n", okMark, config.GetConfigPath())
+		fmt.Printf("  %s Server URL: %s\n", okMark, cfg.ServerURL)
+
+		failed := false
+		if cfg.ProxyURL != "" {
+			fmt.Printf("  %s Proxy: %s\n", okMark, cfg.ProxyURL)
+		}
+		if cfg.TLS != nil {
+			fmt.Println()
+			headerColor.Println("TLS")
+			if !checkTLS(cfg.TLS) {
+				failed = true
+			}
+		}
+
+		fmt.Println()
+		headerColor.Println("Connection")
+		client, err := newClient(cfg)
+		if err != nil {
+			fmt.Printf("  %s %v\n", failMark, err)
+			os.Exit(1)
+		}
+		start := time.Now()
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			fmt.Printf("  %s %v\n", failMark, err)
+			os.Exit(1)
+		}
+		fmt.Printf("  %s Management API reachable in %s (%d accounts)\n", okMark, time.Since(start).Round(time.Millisecond), len(accounts))
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// checkTLS reports on each configured TLS setting and returns false if any
+// of them cannot be loaded.
+func checkTLS(tc *config.TLSConfig) bool {
+	ok := true
+
+	if tc.CABundle != "" {
+		data, err := os.ReadFile(tc.CABundle)
+		if err != nil {
+			fmt.Printf("  %s CA bundle %s: %v\n", failMark, tc.CABundle, err)
+			ok = false
+		} else if n := countPEMCertificates(data); n == 0 {
+			fmt.Printf("  %s CA bundle %s: no certificates found\n", failMark, tc.CABundle)
+			ok = false
+		} else {
+			fmt.Printf("  %s CA bundle %s (%d certificates)\n", okMark, tc.CABundle, n)
+		}
+	}
+
+	if tc.ClientCert != "" || tc.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(tc.ClientCert, tc.ClientKey)
+		switch {
+		case err != nil:
+			fmt.Printf("  %s Client certificate: %v\n", failMark, err)
+			ok = false
+		case cert.Leaf != nil && time.Now().After(cert.Leaf.NotAfter):
+			fmt.Printf("  %s Client certificate %s expired on %s\n", failMark, cert.Leaf.Subject.CommonName, cert.Leaf.NotAfter.Format("2006-01-02"))
+			ok = false
+		case cert.Leaf != nil:
+			fmt.Printf("  %s Client certificate %s (expires %s)\n", okMark, cert.Leaf.Subject.CommonName, cert.Leaf.NotAfter.Format("2006-01-02"))
+		default:
+			fmt.Printf("  %s Client certificate %s\n", okMark, tc.ClientCert)
+		}
+	}
+
+	if tc.ServerName != "" {
+		fmt.Printf("  %s Server name override: %s\n", okMark, tc.ServerName)
+	}
+	if tc.InsecureSkipVerify {
+		fmt.Printf("  %s Certificate verification is DISABLED (insecure_skip_verify)\n", warnMark)
+	}
+
+	return ok
+}
+
+func countPEMCertificates(data []byte) int {
+	n := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return n
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err == nil {
+			n++
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+	"github.com/quaywin/quota-sense-cli/internal/policy"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+var (
+	enforceDryRun   bool
+	enforceInterval time.Duration
+)
+
+var enforceCmd = &cobra.Command{
+	Use:   "enforce",
+	Short: "Disable exhausted accounts and re-enable them after their quota resets",
+	Long: `Applies the exhaustion policy from the config file: accounts whose quota
+falls to a rule's floor are disabled, and re-enabled once the reset time has
+passed and a fresh fetch shows they recovered. Only accounts disabled by this
+command are ever re-enabled. Every change is recorded in the audit log.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		// Decisions, especially re-enabling, must be based on fresh quota.
+		noCache = true
+		cfg, client := mustLoadClient()
+		if cfg.Policy == nil || len(cfg.Policy.Rules) == 0 {
+			errorColor.Println("No policy rules configured; add a \"policy\" block to the config file.")
+			os.Exit(1)
+		}
+
+		for {
+			if !enforceOnce(ctx, cfg, client) && enforceInterval <= 0 {
+				os.Exit(1)
+			}
+			if enforceInterval <= 0 {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(enforceInterval):
+			}
+		}
+	},
+}
+
+// enforceOnce fetches a snapshot, applies the resulting actions and records
+// them in the audit log. It returns false if anything failed.
+func enforceOnce(ctx context.Context, cfg *config.Config, client *quotasense.Client) bool {
+	statePath, err := getPolicyStatePath()
+	if err != nil {
+		errorColor.Printf("Error locating policy state: %v\n", err)
+		return false
+	}
+	auditPath, err := getAuditLogPath(cfg)
+	if err != nil {
+		errorColor.Printf("Error locating audit log: %v\n", err)
+		return false
+	}
+
+	unlock, err := policy.LockState(ctx, statePath)
+	if err != nil {
+		if ctx.Err() != nil {
+			return true
+		}
+		errorColor.Printf("Error locking policy state: %v\n", err)
+		return false
+	}
+	defer unlock()
+
+	state, err := policy.LoadState(statePath)
+	if err != nil {
+		errorColor.Printf("Error loading policy state: %v\n", err)
+		return false
+	}
+
+	snap, err := client.Snapshot(ctx, quotasense.Filter{})
+	if err != nil {
+		errorColor.Printf("Error fetching usage: %v\n", err)
+		return false
+	}
+	if ctx.Err() != nil {
+		return true
+	}
+
+	now := time.Now()
+	results, accounts := policyResults(snap)
+	actions := policy.Evaluate(cfg.Policy.Rules, results, state, now)
+	ok := true
+	records := make([]policy.AuditRecord, 0, len(actions))
+	for _, action := range actions {
+		var err error
+		if !enforceDryRun {
+			switch action.Kind {
+			case policy.Disable:
+				err = client.SetDisabled(ctx, accounts[action.Account.ID], true)
+			case policy.Enable:
+				err = client.SetDisabled(ctx, accounts[action.Account.ID], false)
+			}
+			if err == nil {
+				state.Apply(action)
+			}
+		}
+		records = append(records, policy.NewAuditRecord(action, now, enforceDryRun, err))
+		printPolicyAction(action, err)
+		if err != nil {
+			ok = false
+		}
+	}
+
+	if len(actions) == 0 {
+		fmt.Printf("%s No policy changes (%d accounts checked, %d disabled by policy)\n",
+			now.Local().Format("15:04:05"), len(snap.Accounts), len(state.Disabled))
+	}
+	if enforceDryRun {
+		fmt.Println("Dry run: no changes made.")
+	} else if err := state.Save(statePath); err != nil {
+		errorColor.Printf("Error saving policy state: %v\n", err)
+		ok = false
+	}
+	if err := policy.AppendAudit(auditPath, records...); err != nil {
+		errorColor.Printf("Error writing audit log: %v\n", err)
+		ok = false
+	}
+	return ok
+}
+
+// policyResults converts a snapshot into the policy engine's input, and
+// returns the snapshot's accounts by ID for applying its actions.
+func policyResults(snap *quotasense.Snapshot) ([]policy.Result, map[string]quotasense.Account) {
+	results := make([]policy.Result, len(snap.Accounts))
+	accounts := make(map[string]quotasense.Account, len(snap.Accounts))
+	for i, res := range snap.Accounts {
+		a := res.Account
+		accounts[a.ID] = a
+		results[i] = policy.Result{
+			Account: policy.Account{ID: a.ID, Email: a.Email, Provider: a.Provider, Disabled: a.Disabled},
+			Limits:  make([]policy.Limit, len(res.Limits)),
+			Err:     res.Err,
+		}
+		for j, l := range res.Limits {
+			results[i].Limits[j] = policy.Limit{
+				ModelID:           l.ModelID,
+				Provider:          l.Provider,
+				Group:             l.Group,
+				RemainingFraction: l.RemainingFraction,
+				QuotaUnknown:      l.QuotaUnknown,
+				ResetAt:           l.ResetAt,
+				Blocked:           l.Blocked,
+				Credits:           l.Credits != nil,
+			}
+		}
+	}
+	return results, accounts
+}
+
+func printPolicyAction(action policy.Action, err error) {
+	mark := okMark
+	if err != nil {
+		mark = failMark
+	}
+	line := fmt.Sprintf("  %s %-7s %s (%s): %s", mark, action.Kind, action.Account.Email, action.Account.Provider, action.Reason)
+	if action.Kind == policy.Disable && !action.Entry.ResetAt.IsZero() {
+		line += fmt.Sprintf(", resets %s", action.Entry.ResetAt.Local().Format("2006-01-02 15:04"))
+	}
+	if err != nil {
+		line += fmt.Sprintf(" [%v]", err)
+	}
+	fmt.Println(line)
+}
+
+// getPolicyDir returns the directory in the user config directory where the
+// policy state and audit log are kept, creating it if needed. Unlike the
+// caches, the state must survive, or disabled accounts would be forgotten.
+func getPolicyDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "quota-sense")
+	return dir, os.MkdirAll(dir, 0700)
+}
+
+func getPolicyStatePath() (string, error) {
+	dir, err := getPolicyDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "policy.json"), nil
+}
+
+func getAuditLogPath(cfg *config.Config) (string, error) {
+	if cfg.Policy.AuditLog != "" {
+		return cfg.Policy.AuditLog, nil
+	}
+	dir, err := getPolicyDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+func init() {
+	enforceCmd.Flags().BoolVar(&enforceDryRun, "dry-run", false, "Show and log what would change without changing anything")
+	enforceCmd.Flags().DurationVar(&enforceInterval, "interval", 0, "Keep running and re-check at this interval (e.g. 5m)")
+	rootCmd.AddCommand(enforceCmd)
+}
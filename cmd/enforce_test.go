@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+)
+
+func TestPolicyPaths(t *testing.T) {
+	useTempHome(t)
+	base, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statePath, err := getPolicyStatePath()
+	if err != nil || statePath != filepath.Join(base, "quota-sense", "policy.json") {
+		t.Errorf("getPolicyStatePath() = %q, %v; want it in the user config directory", statePath, err)
+	}
+	if _, err := os.Stat(filepath.Dir(statePath)); err != nil {
+		t.Errorf("policy directory was not created: %v", err)
+	}
+
+	cfg := &config.Config{Policy: &config.PolicyConfig{}}
+	if path, err := getAuditLogPath(cfg); err != nil || path != filepath.Join(base, "quota-sense", "audit.log") {
+		t.Errorf("getAuditLogPath() = %q, %v; want it next to the policy state", path, err)
+	}
+	cfg.Policy.AuditLog = "/var/log/qs-audit.log"
+	if path, _ := getAuditLogPath(cfg); path != cfg.Policy.AuditLog {
+		t.Errorf("getAuditLogPath() = %q; want the configured %q", path, cfg.Policy.AuditLog)
+	}
+}
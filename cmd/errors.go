@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+const (
+	errorBodySnippetLen = 200
+	reasonUnsupported   = "Unsupported provider"
+)
+
+// describeFetchError returns a short, categorized reason for a failed quota
+// fetch, suitable for the model column of an error row.
+func describeFetchError(err error) string {
+	var upstreamErr *quotasense.UpstreamError
+	var mgmtErr *quotasense.ManagementError
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var cachedErr *cachedFetchError
+
+	switch {
+	case errors.As(err, &cachedErr):
+		return cachedErr.reason
+	case errors.Is(err, quotasense.ErrUnsupportedProvider):
+		return reasonUnsupported
+	case errors.As(err, &upstreamErr):
+		switch {
+		case errors.Is(err, quotasense.ErrUnauthorized):
+			return fmt.Sprintf("Auth rejected (%d)", upstreamErr.StatusCode)
+		case upstreamErr.StatusCode >= 500:
+			return fmt.Sprintf("Upstream 5xx (%d)", upstreamErr.StatusCode)
+		default:
+			return fmt.Sprintf("Upstream 4xx (%d)", upstreamErr.StatusCode)
+		}
+	case errors.As(err, &mgmtErr):
+		if errors.Is(err, quotasense.ErrUnauthorized) {
+			return fmt.Sprintf("Token rejected (%d)", mgmtErr.StatusCode)
+		}
+		return fmt.Sprintf("Server error (%d)", mgmtErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "Timeout"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "Decode failure"
+	default:
+		return "Request failed"
+	}
+}
+
+// describeFetchErrorDetail returns the raw error, or the status and a snippet
+// of the response body for upstream and management errors, for --show-errors.
+func describeFetchErrorDetail(err error) string {
+	var upstreamErr *quotasense.UpstreamError
+	var mgmtErr *quotasense.ManagementError
+	var status int
+	var body string
+	switch {
+	case errors.As(err, &upstreamErr):
+		status, body = upstreamErr.StatusCode, upstreamErr.Body
+	case errors.As(err, &mgmtErr):
+		status, body = mgmtErr.StatusCode, mgmtErr.Body
+	default:
+		return err.Error()
+	}
+
+	body = strings.Join(strings.Fields(body), " ")
+	if runes := []rune(body); len(runes) > errorBodySnippetLen {
+		body = string(runes[:errorBodySnippetLen]) + "..."
+	}
+	if body == "" {
+		return fmt.Sprintf("status %d (empty body)", status)
+	}
+	return fmt.Sprintf("status %d: %s", status, body)
+}
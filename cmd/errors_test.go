@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+func TestDescribeFetchError(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{fmt.Errorf("%w: %q", quotasense.ErrUnsupportedProvider, "qwen"), "Unsupported provider"},
+		{&quotasense.UpstreamError{StatusCode: 401}, "Auth rejected (401)"},
+		{&quotasense.UpstreamError{StatusCode: 403}, "Auth rejected (403)"},
+		{&quotasense.UpstreamError{StatusCode: 429}, "Upstream 4xx (429)"},
+		{&quotasense.UpstreamError{StatusCode: 503}, "Upstream 5xx (503)"},
+		{&quotasense.ManagementError{StatusCode: 401}, "Token rejected (401)"},
+		{fmt.Errorf("fetch: %w", &quotasense.ManagementError{StatusCode: 502}), "Server error (502)"},
+		{fmt.Errorf("request: %w", context.DeadlineExceeded), "Timeout"},
+		{fmt.Errorf("failed to unmarshal usage response: %w", syntaxErr), "Decode failure"},
+		{fmt.Errorf("connection refused"), "Request failed"},
+	}
+
+	for _, test := range tests {
+		result := describeFetchError(test.err)
+		if result != test.expected {
+			t.Errorf("describeFetchError(%v) = %q; want %q", test.err, result, test.expected)
+		}
+	}
+}
+
+func TestDescribeFetchErrorDetail(t *testing.T) {
+	err := &quotasense.UpstreamError{StatusCode: 429, Body: "{\n  \"error\": \"rate limited\"\n}"}
+	if got := describeFetchErrorDetail(err); got != `status 429: { "error": "rate limited" }` {
+		t.Errorf("describeFetchErrorDetail = %q", got)
+	}
+
+	long := &quotasense.UpstreamError{StatusCode: 500, Body: strings.Repeat("x", 500)}
+	if got := describeFetchErrorDetail(long); len(got) != len("status 500: ")+errorBodySnippetLen+3 {
+		t.Errorf("describeFetchErrorDetail did not truncate body: %d chars", len(got))
+	}
+}
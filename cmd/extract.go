@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxBinarySize bounds the extracted binary, so that a corrupt or hostile
+// archive cannot fill the disk.
+const maxBinarySize = 256 << 20
+
+// extractBinary copies the binary named binaryName from the tar.gz or zip
+// archive into destDir and returns its path. Only an entry at the archive
+// root with exactly that name is extracted; everything else is ignored.
+func extractBinary(archivePath, binaryName, destDir string) (string, error) {
+	dest := filepath.Join(destDir, binaryName)
+	var err error
+	if strings.HasSuffix(archivePath, ".zip") {
+		err = extractFromZip(archivePath, binaryName, dest)
+	} else {
+		err = extractFromTarGz(archivePath, binaryName, dest)
+	}
+	if err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+func extractFromTarGz(archivePath, binaryName, dest string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read tar.gz: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s not found in archive", binaryName)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar.gz: %w", err)
+		}
+		if !isArchiveEntry(hdr.Name, binaryName) {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%s in archive is not a regular file", binaryName)
+		}
+		if hdr.Size > maxBinarySize {
+			return fmt.Errorf("%s in archive is too large (%d bytes)", binaryName, hdr.Size)
+		}
+		return writeBinary(dest, tr)
+	}
+}
+
+func extractFromZip(archivePath, binaryName, dest string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to read zip: %w", err)
+	}
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		if !isArchiveEntry(zf.Name, binaryName) {
+			continue
+		}
+		if !zf.Mode().IsRegular() {
+			return fmt.Errorf("%s in archive is not a regular file", binaryName)
+		}
+		if zf.UncompressedSize64 > maxBinarySize {
+			return fmt.Errorf("%s in archive is too large (%d bytes)", binaryName, zf.UncompressedSize64)
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read zip: %w", err)
+		}
+		defer rc.Close()
+		return writeBinary(dest, rc)
+	}
+	return fmt.Errorf("%s not found in archive", binaryName)
+}
+
+// isArchiveEntry reports whether an archive entry name refers to binaryName
+// at the archive root. Absolute paths, parent references and entries in
+// subdirectories never match.
+func isArchiveEntry(name, binaryName string) bool {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || strings.Contains(name, "..") {
+		return false
+	}
+	return path.Clean(name) == binaryName
+}
+
+// writeBinary copies at most maxBinarySize bytes from r to a new executable
+// file at dest, failing if r holds more than that.
+func writeBinary(dest string, r io.Reader) error {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, maxBinarySize+1))
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > maxBinarySize {
+		err = fmt.Errorf("extracted binary exceeds %d bytes", maxBinarySize)
+	}
+	if err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return nil
+}
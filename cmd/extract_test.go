@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type archiveEntry struct {
+	name string
+	body string
+	dir  bool
+}
+
+func writeTarGz(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0755, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.dir {
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if !e.dir {
+			tw.Write([]byte(e.body))
+		}
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func writeZip(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(e.body))
+	}
+	zw.Close()
+}
+
+func TestExtractBinary(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "../qs", body: "escape"},
+		{name: "/tmp/qs", body: "absolute"},
+		{name: "bin/qs", body: "nested"},
+		{name: "README.md", body: "docs"},
+		{name: "./qs", body: "binary"},
+	}
+
+	for _, ext := range []string{"tar.gz", "zip"} {
+		dir := t.TempDir()
+		archive := filepath.Join(dir, "archive."+ext)
+		if ext == "zip" {
+			writeZip(t, archive, entries)
+		} else {
+			writeTarGz(t, archive, entries)
+		}
+
+		destDir := filepath.Join(dir, "out")
+		os.Mkdir(destDir, 0700)
+		got, err := extractBinary(archive, "qs", destDir)
+		if err != nil {
+			t.Fatalf("extractBinary(%s) returned error: %v", ext, err)
+		}
+		data, _ := os.ReadFile(got)
+		if string(data) != "binary" {
+			t.Errorf("extractBinary(%s) extracted %q; want the root qs entry", ext, data)
+		}
+		files, _ := os.ReadDir(destDir)
+		if len(files) != 1 {
+			t.Errorf("extractBinary(%s) wrote %d files; want 1", ext, len(files))
+		}
+		if _, err := os.Stat(filepath.Join(dir, "qs")); err == nil {
+			t.Errorf("extractBinary(%s) wrote outside the destination", ext)
+		}
+	}
+}
+
+func TestExtractBinaryRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+	}{
+		{"missing", []archiveEntry{{name: "bin/qs", body: "nested"}}},
+		{"directory", []archiveEntry{{name: "qs/", dir: true}}},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		archive := filepath.Join(dir, "archive.tar.gz")
+		writeTarGz(t, archive, test.entries)
+		if _, err := extractBinary(archive, "qs", dir); err == nil {
+			t.Errorf("extractBinary accepted an archive with %s qs", test.name)
+		}
+	}
+}
+
+func TestIsArchiveEntry(t *testing.T) {
+	tests := map[string]bool{
+		"qs":         true,
+		"./qs":       true,
+		"qs.exe":     false,
+		"../qs":      false,
+		"/qs":        false,
+		`..\qs`:      false,
+		"dist/qs":    false,
+		"dist/../qs": false,
+	}
+	for name, expected := range tests {
+		if got := isArchiveEntry(name, "qs"); got != expected {
+			t.Errorf("isArchiveEntry(%q) = %v; want %v", name, got, expected)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// selfTestTimeout bounds how long a newly installed binary may take to print
+// its version.
+const selfTestTimeout = 10 * time.Second
+
+// previousBinaryPath is where the binary replaced by the last update is kept
+// for --rollback.
+func previousBinaryPath(exePath string) string {
+	return exePath + ".old"
+}
+
+// installBinary replaces exePath with newBinary, keeping the current binary
+// as the previous one. The running executable is renamed rather than
+// overwritten, which also works on Windows.
+func installBinary(newBinary, exePath string) error {
+	oldPath := previousBinaryPath(exePath)
+	_ = os.Remove(oldPath)
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return fmt.Errorf("failed to keep the current binary: %w", err)
+	}
+
+	err := os.Rename(newBinary, exePath)
+	if err != nil {
+		// Rename fails across devices (e.g. a tmpfs /tmp); copy instead.
+		err = moveFile(newBinary, exePath)
+	}
+	if err == nil {
+		err = os.Chmod(exePath, 0755)
+	}
+	if err != nil {
+		if restoreErr := restorePrevious(exePath); restoreErr != nil {
+			return fmt.Errorf("%w (restoring the previous binary also failed: %v)", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// restorePrevious puts the previous binary back in place of exePath,
+// discarding the current one.
+func restorePrevious(exePath string) error {
+	_ = os.Remove(exePath)
+	return os.Rename(previousBinaryPath(exePath), exePath)
+}
+
+// swapWithPrevious exchanges exePath and the previous binary, so that a
+// rollback can itself be undone with another rollback.
+func swapWithPrevious(exePath string) error {
+	oldPath := previousBinaryPath(exePath)
+	if _, err := os.Stat(oldPath); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no previous version to roll back to (%s not found)", oldPath)
+	}
+
+	tmpPath := exePath + ".rollback"
+	_ = os.Remove(tmpPath)
+	if err := os.Rename(exePath, tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, exePath); err != nil {
+		_ = os.Rename(tmpPath, exePath)
+		return err
+	}
+	return os.Rename(tmpPath, oldPath)
+}
+
+// selfTest runs the binary's version command and checks that it reports
+// wantVersion, if given.
+func selfTest(exePath, wantVersion string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, exePath, "version").Output()
+	got := strings.TrimSpace(string(out))
+	if err != nil {
+		return got, fmt.Errorf("running %s version: %w", exePath, err)
+	}
+	if !strings.HasPrefix(got, "QuotaSense CLI ") {
+		return got, fmt.Errorf("unexpected version output %q", got)
+	}
+	if wantVersion != "" && strings.TrimPrefix(got, "QuotaSense CLI ") != wantVersion {
+		return got, fmt.Errorf("installed binary reports %q, want %s", got, wantVersion)
+	}
+	return got, nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeBinary writes a shell script that prints a version like qs does.
+func fakeBinary(t *testing.T, path, output string) {
+	t.Helper()
+	script := "#!/bin/sh\necho '" + output + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstallSelfTestAndRollback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as fake binaries")
+	}
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "qs")
+	fakeBinary(t, exePath, "QuotaSense CLI v1.0.0")
+	newBinary := filepath.Join(dir, "new-qs")
+	fakeBinary(t, newBinary, "QuotaSense CLI v1.1.0")
+
+	if err := installBinary(newBinary, exePath); err != nil {
+		t.Fatalf("installBinary returned error: %v", err)
+	}
+	if got, err := selfTest(exePath, "v1.1.0"); err != nil {
+		t.Fatalf("selfTest after install = %q, %v", got, err)
+	}
+	if _, err := selfTest(exePath, "v2.0.0"); err == nil {
+		t.Errorf("selfTest accepted the wrong version")
+	}
+
+	if err := swapWithPrevious(exePath); err != nil {
+		t.Fatalf("swapWithPrevious returned error: %v", err)
+	}
+	if got, err := selfTest(exePath, "v1.0.0"); err != nil {
+		t.Errorf("selfTest after rollback = %q, %v", got, err)
+	}
+	if got, err := selfTest(previousBinaryPath(exePath), "v1.1.0"); err != nil {
+		t.Errorf("previous binary after rollback = %q, %v; want the rolled-back version kept", got, err)
+	}
+
+	if err := restorePrevious(exePath); err != nil {
+		t.Fatalf("restorePrevious returned error: %v", err)
+	}
+	if got, err := selfTest(exePath, "v1.1.0"); err != nil {
+		t.Errorf("selfTest after restore = %q, %v", got, err)
+	}
+	if err := swapWithPrevious(exePath); err == nil {
+		t.Errorf("swapWithPrevious succeeded without a previous binary")
+	}
+}
+
+func TestSelfTestRejectsBrokenBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as fake binaries")
+	}
+	exePath := filepath.Join(t.TempDir(), "qs")
+	fakeBinary(t, exePath, "segmentation fault")
+	if _, err := selfTest(exePath, ""); err == nil {
+		t.Errorf("selfTest accepted unexpected output")
+	}
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/internal/utils"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modelsProviders []string
+	modelsAccounts  []string
+	modelsMatrix    bool
+	modelsWide      bool
+)
+
+// catalogueEntry is one model available to one account.
+type catalogueEntry struct {
+	Account quotasense.Account `json:"account"`
+	quotasense.Limit
+}
+
+// matrixRow is one model in the models × accounts view, with the remaining
+// fraction for each account ID that can serve it, or nil if the account
+// reports no quota for it.
+type matrixRow struct {
+	ModelID     string              `json:"model_id"`
+	Provider    string              `json:"provider"`
+	DisplayName string              `json:"display_name,omitempty"`
+	Accounts    map[string]*float64 `json:"accounts"`
+}
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the models each account can access",
+	Long: `Lists every model or rate-limit window each account reports, with its
+display name, remaining quota and reset time. Use --wide to add the other
+details the provider reports for each model, such as token limits, and
+--matrix for a models × accounts view showing which accounts can serve which
+model.`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: checkOutputFormat,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		_, client := mustLoadClient()
+		snap, err := client.Snapshot(ctx, quotasense.Filter{Providers: modelsProviders, Accounts: modelsAccounts})
+		if err != nil {
+			errorColor.Printf("Error fetching models: %v\n", err)
+			os.Exit(1)
+		}
+		if len(snap.Accounts) == 0 {
+			errorColor.Println("No accounts match the selection")
+			os.Exit(1)
+		}
+
+		if modelsMatrix {
+			rows := buildModelMatrix(snap.Accounts)
+			if outputFormat == outputJSON {
+				accounts := make([]quotasense.Account, len(snap.Accounts))
+				for i, res := range snap.Accounts {
+					accounts[i] = res.Account
+				}
+				printJSON(map[string]any{"accounts": accounts, "models": rows})
+				return
+			}
+			printModelMatrix(snap.Accounts, rows)
+			return
+		}
+
+		if outputFormat == outputJSON {
+			var entries []catalogueEntry
+			for _, res := range snap.Accounts {
+				for _, limit := range catalogueLimits(res.Limits) {
+					entries = append(entries, catalogueEntry{Account: res.Account, Limit: limit})
+				}
+			}
+			printJSON(entries)
+			return
+		}
+		printModelCatalogue(snap.Accounts)
+	},
+}
+
+// catalogueLimits drops entries that are not models, such as credit
+// balances.
+func catalogueLimits(limits []quotasense.Limit) []quotasense.Limit {
+	var out []quotasense.Limit
+	for _, l := range limits {
+		if l.Credits == nil {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func printModelCatalogue(results []quotasense.AccountQuota) {
+	header := fmt.Sprintf("%-40s | %-15s | %-35s | %-30s | %-10s | %-15s", "Account (Email)", "Provider", "Model", "Display Name", "Remaining", "Reset In")
+	if modelsWide {
+		header += " | Details"
+	}
+	headerColor.Println(header)
+	headerColor.Println(strings.Repeat("-", 160))
+	for _, res := range results {
+		a := res.Account
+		rowColor := color.New(color.FgWhite)
+		if a.Disabled {
+			rowColor = color.New(color.FgHiBlack)
+		}
+		if res.Err != nil {
+			printModelRowError(a, res.Err)
+			continue
+		}
+		for _, l := range catalogueLimits(res.Limits) {
+			pct := int(l.RemainingFraction * 100)
+			remaining := fmt.Sprintf("%d%%", pct)
+			quotaColor := utils.GetQuotaColor(pct)
+			switch {
+			case l.Blocked:
+				remaining, quotaColor = "Blocked", utils.GetQuotaColor(0)
+			case l.QuotaUnknown:
+				remaining, quotaColor = "-", rowColor
+			}
+			if a.Disabled {
+				quotaColor = rowColor
+			}
+			rowColor.Printf("%-40s | %-15s | %-35s | %-30s | ", a.Email, a.Provider, l.ModelID, orDash(l.DisplayName))
+			quotaColor.Printf("%-10s", remaining)
+			rowColor.Printf(" | %-15s", utils.FormatResetIn(l.ResetAt))
+			if modelsWide {
+				rowColor.Printf(" | %s", orDash(formatModelMetadata(l.Metadata)))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// formatModelMetadata renders per-model metadata as sorted key=value pairs.
+// Nested values are shown as compact JSON.
+func formatModelMetadata(metadata map[string]any) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		var value string
+		switch v := metadata[k].(type) {
+		case string:
+			value = v
+		case float64:
+			if v == math.Trunc(v) {
+				value = utils.FormatCount(int64(v))
+			} else {
+				value = fmt.Sprint(v)
+			}
+		default:
+			data, _ := json.Marshal(v)
+			value = string(data)
+		}
+		parts[i] = k + "=" + value
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printModelRowError(a quotasense.Account, err error) {
+	c := errorColor
+	if a.Disabled {
+		c = color.New(color.FgHiBlack)
+	}
+	c.Printf("%-40s | %-15s | %-35s | %-30s | %-10s | %-15s\n", a.Email, a.Provider, describeFetchError(err), "-", "-", "-")
+}
+
+// buildModelMatrix collects every model reported by any account, sorted by
+// provider and model ID.
+func buildModelMatrix(results []quotasense.AccountQuota) []matrixRow {
+	index := make(map[string]int)
+	var rows []matrixRow
+	for _, res := range results {
+		for _, l := range catalogueLimits(res.Limits) {
+			key := l.Provider + "\x00" + l.ModelID
+			i, ok := index[key]
+			if !ok {
+				i = len(rows)
+				index[key] = i
+				rows = append(rows, matrixRow{ModelID: l.ModelID, Provider: l.Provider, Accounts: make(map[string]*float64)})
+			}
+			if rows[i].DisplayName == "" {
+				rows[i].DisplayName = l.DisplayName
+			}
+			var fraction *float64
+			switch {
+			case l.Blocked:
+				fraction = new(float64)
+			case !l.QuotaUnknown:
+				fraction = &l.RemainingFraction
+			}
+			rows[i].Accounts[res.Account.ID] = fraction
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Provider != rows[j].Provider {
+			return rows[i].Provider < rows[j].Provider
+		}
+		return rows[i].ModelID < rows[j].ModelID
+	})
+	return rows
+}
+
+// printModelMatrix prints one row per model and one numbered column per
+// account, with a legend mapping the numbers to accounts. Accounts that
+// cannot serve a model show "·" and accounts with unknown quota show "-".
+func printModelMatrix(results []quotasense.AccountQuota, rows []matrixRow) {
+	header := fmt.Sprintf("%-35s | %-15s", "Model", "Provider")
+	for i := range results {
+		header += fmt.Sprintf(" | %-5s", fmt.Sprintf("#%d", i+1))
+	}
+	headerColor.Println(header)
+	headerColor.Println(strings.Repeat("-", len([]rune(header))))
+
+	for _, row := range rows {
+		fmt.Printf("%-35s | %-15s", row.ModelID, row.Provider)
+		for _, res := range results {
+			fmt.Print(" | ")
+			fraction, ok := row.Accounts[res.Account.ID]
+			switch {
+			case !ok:
+				color.New(color.FgHiBlack).Printf("%-5s", "·")
+			case fraction == nil:
+				fmt.Printf("%-5s", "-")
+			case res.Account.Disabled:
+				color.New(color.FgHiBlack).Printf("%-5s", fmt.Sprintf("%d%%", int(*fraction*100)))
+			default:
+				pct := int(*fraction * 100)
+				utils.GetQuotaColor(pct).Printf("%-5s", fmt.Sprintf("%d%%", pct))
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	color.New(color.FgHiBlack).Println("· not available to the account, - quota unknown")
+	for i, res := range results {
+		label := fmt.Sprintf("#%-3d %s (%s)", i+1, res.Account.Email, res.Account.Provider)
+		switch {
+		case res.Err != nil:
+			errorColor.Printf("%s: %s\n", label, describeFetchError(res.Err))
+		case res.Account.Disabled:
+			color.New(color.FgHiBlack).Printf("%s (disabled)\n", label)
+		default:
+			fmt.Println(label)
+		}
+	}
+}
+
+func init() {
+	addOutputFlag(modelsCmd)
+	modelsCmd.Flags().StringSliceVarP(&modelsProviders, "provider", "p", nil, "Only show accounts of this provider (repeatable)")
+	modelsCmd.Flags().StringSliceVarP(&modelsAccounts, "account", "a", nil, "Only show accounts matching this email glob or ID (repeatable)")
+	modelsCmd.Flags().BoolVar(&modelsMatrix, "matrix", false, "Show a models × accounts matrix")
+	modelsCmd.Flags().BoolVar(&modelsWide, "wide", false, "Add a column with the other details the provider reports for each model")
+	rootCmd.AddCommand(modelsCmd)
+}
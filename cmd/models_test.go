@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+func TestBuildModelMatrix(t *testing.T) {
+	results := []quotasense.AccountQuota{
+		{Account: quotasense.Account{ID: "a.json"}, Limits: []quotasense.Limit{
+			{ModelID: "gemini-3-pro", Provider: "antigravity", DisplayName: "Gemini 3 Pro", RemainingFraction: 0.5},
+			{ModelID: "claude-sonnet", Provider: "antigravity", RemainingFraction: 0.9, Blocked: true},
+		}},
+		{Account: quotasense.Account{ID: "b.json"}, Limits: []quotasense.Limit{
+			{ModelID: "gemini-3-pro", Provider: "antigravity", RemainingFraction: 1},
+			{ModelID: "chat_20706", Provider: "antigravity", QuotaUnknown: true},
+			{ModelID: "credits", Provider: "codex", Credits: &quotasense.Credits{}},
+		}},
+	}
+
+	rows := buildModelMatrix(results)
+	if len(rows) != 3 {
+		t.Fatalf("buildModelMatrix returned %d rows; want 3", len(rows))
+	}
+	chat, sonnet, pro := rows[0], rows[1], rows[2]
+	if fraction, ok := chat.Accounts["b.json"]; chat.ModelID != "chat_20706" || !ok || fraction != nil {
+		t.Errorf("chat_20706 row = %+v; want b.json with unknown quota", chat)
+	}
+	if sonnet.ModelID != "claude-sonnet" || len(sonnet.Accounts) != 1 || *sonnet.Accounts["a.json"] != 0 {
+		t.Errorf("claude-sonnet row = %+v; want a.json blocked at 0", sonnet)
+	}
+	if pro.DisplayName != "Gemini 3 Pro" || *pro.Accounts["a.json"] != 0.5 || *pro.Accounts["b.json"] != 1 {
+		t.Errorf("gemini-3-pro row = %+v", pro)
+	}
+}
+
+func TestFormatModelMetadata(t *testing.T) {
+	metadata := map[string]any{
+		"maxTokens":      float64(1048576),
+		"supportsImages": true,
+		"temperature":    0.7,
+		"tier":           "high",
+		"modalities":     []any{"text", "image"},
+	}
+	expected := `maxTokens=1,048,576, modalities=["text","image"], supportsImages=true, temperature=0.7, tier=high`
+	if got := formatModelMetadata(metadata); got != expected {
+		t.Errorf("formatModelMetadata = %q; want %q", got, expected)
+	}
+	if got := formatModelMetadata(nil); got != "" {
+		t.Errorf("formatModelMetadata(nil) = %q; want empty", got)
+	}
+}
+
+func TestBestInGroupSkipsUnknownQuota(t *testing.T) {
+	entries := bestInGroup([]quotasense.Limit{
+		{ModelID: "gemini-3-flash", Group: "Gemini 3", QuotaUnknown: true},
+		{ModelID: "gemini-3-pro", Group: "Gemini 3", RemainingFraction: 0.7},
+		{ModelID: "gemini-3-lite", Group: "Gemini 3", QuotaUnknown: true},
+	})
+	if len(entries) != 1 || entries[0].limit.ModelID != "gemini-3-pro" {
+		t.Errorf("bestInGroup = %+v; want the known gemini-3-pro limit", entries)
+	}
+}
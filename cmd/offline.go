@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/cache"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+var offlineMode bool
+
+// cachedSnapshot is the on-disk form of the last good snapshot.
+type cachedSnapshot struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Accounts  []cachedAccount `json:"accounts"`
+}
+
+type cachedAccount struct {
+	Account     quotasense.Account `json:"account"`
+	Limits      []quotasense.Limit `json:"limits,omitempty"`
+	LatencyMs   int64              `json:"latency_ms,omitempty"`
+	ErrorReason string             `json:"error_reason,omitempty"`
+	ErrorDetail string             `json:"error_detail,omitempty"`
+	// FetchedAt is when Limits were fetched, which is older than the
+	// snapshot for accounts kept from a previous save.
+	FetchedAt time.Time `json:"fetched_at,omitzero"`
+}
+
+// cachedFetchError stands in for a per-account error restored from the
+// snapshot cache, keeping the reason that was shown when it was saved.
+type cachedFetchError struct {
+	reason string
+	detail string
+}
+
+func (e *cachedFetchError) Error() string {
+	return e.detail
+}
+
+func (e *cachedFetchError) Unwrap() error {
+	if e.reason == reasonUnsupported {
+		return quotasense.ErrUnsupportedProvider
+	}
+	return nil
+}
+
+// getSnapshotCachePath returns where the last good snapshot is kept, next to
+// the response cache in the user cache directory.
+func getSnapshotCachePath() (string, error) {
+	dir, err := cache.DefaultDir("offline")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snapshot.json"), nil
+}
+
+// saveSnapshotCache stores snap as the last known good snapshot. A snapshot
+// in which every account failed is not saved, and accounts that failed this
+// time keep the limits from the previous save. Failures are ignored since the
+// cache is only a fallback.
+func saveSnapshotCache(snap *quotasense.Snapshot) {
+	failed := 0
+	for _, res := range snap.Accounts {
+		if res.Err != nil {
+			failed++
+		}
+	}
+	if len(snap.Accounts) > 0 && failed == len(snap.Accounts) {
+		return
+	}
+
+	path, err := getSnapshotCachePath()
+	if err != nil {
+		return
+	}
+	previous := make(map[string]cachedAccount)
+	if failed > 0 {
+		if data, err := os.ReadFile(path); err == nil {
+			var old cachedSnapshot
+			if json.Unmarshal(data, &old) == nil {
+				for _, ca := range old.Accounts {
+					if ca.ErrorReason == "" {
+						if ca.FetchedAt.IsZero() {
+							ca.FetchedAt = old.FetchedAt
+						}
+						previous[ca.Account.ID] = ca
+					}
+				}
+			}
+		}
+	}
+
+	saved := cachedSnapshot{
+		FetchedAt: snap.FetchedAt,
+		Accounts:  make([]cachedAccount, len(snap.Accounts)),
+	}
+	for i, res := range snap.Accounts {
+		if prev, ok := previous[res.Account.ID]; ok && res.Err != nil {
+			prev.Account = res.Account
+			saved.Accounts[i] = prev
+			continue
+		}
+		ca := cachedAccount{
+			Account:   res.Account,
+			Limits:    res.Limits,
+			LatencyMs: res.Latency.Milliseconds(),
+			FetchedAt: res.FetchedAt,
+		}
+		if res.Err != nil {
+			ca.ErrorReason = describeFetchError(res.Err)
+			ca.ErrorDetail = describeFetchErrorDetail(res.Err)
+		}
+		saved.Accounts[i] = ca
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
+
+// loadSnapshotCache returns the last saved snapshot, or an error if there is
+// none. The snapshot's FetchedAt is that of its oldest account data, so that
+// the staleness banner never understates the age.
+func loadSnapshotCache() (*quotasense.Snapshot, error) {
+	path, err := getSnapshotCachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved cachedSnapshot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+
+	snap := &quotasense.Snapshot{
+		FetchedAt: saved.FetchedAt,
+		Accounts:  make([]quotasense.AccountQuota, len(saved.Accounts)),
+	}
+	for i, ca := range saved.Accounts {
+		res := quotasense.AccountQuota{
+			Account:   ca.Account,
+			Limits:    ca.Limits,
+			Latency:   time.Duration(ca.LatencyMs) * time.Millisecond,
+			FetchedAt: ca.FetchedAt,
+		}
+		if res.FetchedAt.IsZero() {
+			res.FetchedAt = saved.FetchedAt
+		}
+		if ca.ErrorReason != "" {
+			res.Err = &cachedFetchError{reason: ca.ErrorReason, detail: ca.ErrorDetail}
+		} else if res.FetchedAt.Before(snap.FetchedAt) {
+			snap.FetchedAt = res.FetchedAt
+		}
+		snap.Accounts[i] = res
+	}
+	return snap, nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+// useTempHome points the home, cache and config directories at a fresh
+// temporary directory.
+func useTempHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+}
+
+func TestSnapshotCacheRoundTrip(t *testing.T) {
+	useTempHome(t)
+
+	if _, err := loadSnapshotCache(); err == nil {
+		t.Fatalf("loadSnapshotCache succeeded without a cache file")
+	}
+
+	fetchedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	resetAt := fetchedAt.Add(3 * time.Hour)
+	saveSnapshotCache(&quotasense.Snapshot{
+		FetchedAt: fetchedAt,
+		Accounts: []quotasense.AccountQuota{
+			{
+				Account: quotasense.Account{ID: "g.json", Email: "gem@example.com", Provider: "gemini-cli"},
+				Limits: []quotasense.Limit{
+					{ModelID: "gemini-2.5-pro", Group: "Gemini Pro", RemainingFraction: 0.4, ResetAt: resetAt},
+				},
+			},
+			{
+				Account: quotasense.Account{ID: "c.json", Email: "codex@example.com", Provider: "codex"},
+				Err:     &quotasense.UpstreamError{Provider: "codex", StatusCode: 401},
+			},
+			{
+				Account: quotasense.Account{ID: "q.json", Email: "qwen@example.com", Provider: "qwen"},
+				Err:     fmt.Errorf("%w: %q", quotasense.ErrUnsupportedProvider, "qwen"),
+			},
+		},
+	})
+
+	snap, err := loadSnapshotCache()
+	if err != nil {
+		t.Fatalf("loadSnapshotCache returned error: %v", err)
+	}
+	if !snap.FetchedAt.Equal(fetchedAt) || len(snap.Accounts) != 3 {
+		t.Fatalf("loadSnapshotCache = %+v", snap)
+	}
+
+	limit := snap.Accounts[0].Limits[0]
+	if limit.ModelID != "gemini-2.5-pro" || limit.RemainingFraction != 0.4 || !limit.ResetAt.Equal(resetAt) {
+		t.Errorf("restored limit = %+v", limit)
+	}
+	if got := describeFetchError(snap.Accounts[1].Err); got != "Auth rejected (401)" {
+		t.Errorf("restored error reason = %q", got)
+	}
+	if !errors.Is(snap.Accounts[2].Err, quotasense.ErrUnsupportedProvider) {
+		t.Errorf("restored unsupported error = %v", snap.Accounts[2].Err)
+	}
+}
+
+func TestSaveSnapshotCacheKeepsLastGood(t *testing.T) {
+	useTempHome(t)
+
+	goodAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	gem := quotasense.Account{ID: "g.json", Email: "gem@example.com", Provider: "gemini-cli"}
+	codex := quotasense.Account{ID: "c.json", Email: "codex@example.com", Provider: "codex"}
+	limits := []quotasense.Limit{{ModelID: "gemini-2.5-pro", Group: "Gemini Pro", RemainingFraction: 0.4}}
+	outage := &quotasense.UpstreamError{Provider: "gemini-cli", StatusCode: 503}
+
+	saveSnapshotCache(&quotasense.Snapshot{FetchedAt: goodAt, Accounts: []quotasense.AccountQuota{
+		{Account: gem, Limits: limits},
+		{Account: codex, Limits: limits},
+	}})
+
+	// Every account failed: the previous snapshot is kept as it is.
+	saveSnapshotCache(&quotasense.Snapshot{FetchedAt: goodAt.Add(time.Hour), Accounts: []quotasense.AccountQuota{
+		{Account: gem, Err: outage},
+		{Account: codex, Err: outage},
+	}})
+	snap, err := loadSnapshotCache()
+	if err != nil || !snap.FetchedAt.Equal(goodAt) || snap.Accounts[0].Err != nil {
+		t.Fatalf("loadSnapshotCache after outage = %+v, %v; want the last good snapshot", snap, err)
+	}
+
+	// One account failed: it keeps its previous limits and fetch time.
+	laterAt := goodAt.Add(2 * time.Hour)
+	saveSnapshotCache(&quotasense.Snapshot{FetchedAt: laterAt, Accounts: []quotasense.AccountQuota{
+		{Account: gem, Err: outage},
+		{Account: codex, Limits: limits, FetchedAt: laterAt},
+	}})
+	snap, err = loadSnapshotCache()
+	if err != nil {
+		t.Fatalf("loadSnapshotCache returned error: %v", err)
+	}
+	if res := snap.Accounts[0]; res.Err != nil || len(res.Limits) != 1 || !res.FetchedAt.Equal(goodAt) {
+		t.Errorf("failed account = %+v; want its previous limits from %v", res, goodAt)
+	}
+	if res := snap.Accounts[1]; !res.FetchedAt.Equal(laterAt) {
+		t.Errorf("refreshed account fetched at %v; want %v", res.FetchedAt, laterAt)
+	}
+	if !snap.FetchedAt.Equal(goodAt) {
+		t.Errorf("snapshot FetchedAt = %v; want the oldest data %v", snap.FetchedAt, goodAt)
+	}
+}
+
+func TestFillTimedOut(t *testing.T) {
+	useTempHome(t)
+
+	cachedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	gem := quotasense.Account{ID: "g.json", Email: "gem@example.com", Provider: "gemini-cli"}
+	codex := quotasense.Account{ID: "c.json", Email: "codex@example.com", Provider: "codex"}
+	limits := []quotasense.Limit{{ModelID: "gemini-2.5-pro", Group: "Gemini Pro", RemainingFraction: 0.4}}
+	saveSnapshotCache(&quotasense.Snapshot{FetchedAt: cachedAt, Accounts: []quotasense.AccountQuota{
+		{Account: gem, Limits: limits},
+		{Account: codex, Limits: limits},
+	}})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	now := time.Now()
+	snap, stale, ok := fillTimedOut(ctx, &quotasense.Snapshot{FetchedAt: now, Accounts: []quotasense.AccountQuota{
+		{Account: gem, Err: ctx.Err()},
+		{Account: codex, Limits: limits, FetchedAt: now},
+	}})
+	if !ok || !stale || !snap.FetchedAt.Equal(cachedAt) {
+		t.Fatalf("fillTimedOut = %v, %v, FetchedAt %v; want stale as of %v", stale, ok, snap.FetchedAt, cachedAt)
+	}
+	if res := snap.Accounts[0]; res.Err != nil || len(res.Limits) != 1 {
+		t.Errorf("timed out account = %+v; want cached limits", res)
+	}
+	if res := snap.Accounts[1]; !res.FetchedAt.Equal(now) {
+		t.Errorf("finished account = %+v; want it unchanged", res)
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats accepted by --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var outputFormat string
+
+// addOutputFlag registers --output on cmd and its subcommands.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table or json")
+}
+
+// checkOutputFormat rejects unknown --output values before any work is done.
+func checkOutputFormat(cmd *cobra.Command, args []string) error {
+	switch outputFormat {
+	case outputTable, outputJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (want table or json)", outputFormat)
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		errorColor.Printf("Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,18 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"sort"
-	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/fatih/color"
-	"github.com/quaywin/quota-sense-cli/internal/api"
 	"github.com/quaywin/quota-sense-cli/internal/config"
-	"github.com/quaywin/quota-sense-cli/internal/models"
 	"github.com/quaywin/quota-sense-cli/internal/utils"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +23,10 @@ var (
 	successColor = color.New(color.FgGreen, color.Bold)
 	errorColor   = color.New(color.FgRed, color.Bold)
 	fullMode     bool
+	verboseMode  bool
+	showErrors   bool
+	concurrency  int
+	timeout      time.Duration
 )
 
 var rootCmd = &cobra.Command{
@@ -35,17 +40,29 @@ var rootCmd = &cobra.Command{
 		checkAndNotifyUpdate()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		// Prompt before installing the Ctrl-C handler so that Ctrl-C at the
+		// prompt exits straight away.
 		cfg, err := config.LoadConfig()
-		if err != nil {
+		prompted := err != nil && !offlineMode
+		if prompted {
 			cfg, err = config.PromptConfig()
 			if err != nil {
 				errorColor.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
+		}
 
-			client := api.NewClient(cfg)
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		if prompted {
+			client, err := newClient(cfg)
+			if err != nil {
+				errorColor.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
 			fmt.Println("Verifying connection...")
-			if err := client.CheckConnection(); err != nil {
+			if err := client.CheckConnection(ctx); err != nil {
 				errorColor.Printf("Connection failed: %v\n", err)
 				os.Exit(1)
 			}
@@ -57,35 +74,188 @@ var rootCmd = &cobra.Command{
 			successColor.Println("Configuration saved successfully!")
 		}
 
-		displayQuota(cfg)
+		displayQuota(ctx, cfg)
 	},
 }
 
+// commandContext returns a context that is cancelled on Ctrl-C or when the
+// --timeout deadline passes. A second Ctrl-C terminates the process. Prompts
+// read while the context is active must use confirm, which gives up when it
+// is cancelled, or the first Ctrl-C is swallowed.
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// formatCredits returns a short description of a credit balance.
+func formatCredits(c *quotasense.Credits) string {
+	switch {
+	case c == nil:
+		return "-"
+	case c.Unlimited:
+		return "Unlimited"
+	case c.Balance != nil:
+		return fmt.Sprintf("%.2f", *c.Balance)
+	case c.HasCredits:
+		return "Available"
+	default:
+		return "None"
+	}
+}
+
+// lowerLimit reports whether a has less quota left than b. Known quota is
+// always lower than unknown quota.
+func lowerLimit(a, b quotasense.Limit) bool {
+	if a.QuotaUnknown || b.QuotaUnknown {
+		return !a.QuotaUnknown
+	}
+	return a.RemainingFraction < b.RemainingFraction
+}
+
 type displayEntry struct {
-	limit            models.ModelLimit
+	limit            quotasense.Limit
 	displayModelName string
 }
 
-type accountResult struct {
-	file        models.AuthFile
-	err         error
-	bestInGroup map[string]displayEntry
+// bestInGroup keeps the lowest remaining limit per display group, or every
+// limit in full mode. Models without a group are hidden outside full mode,
+// and a limit with unknown quota is only kept if no other limit is known.
+func bestInGroup(limits []quotasense.Limit) []displayEntry {
+	var keys []string
+	best := make(map[string]displayEntry)
+	for _, limit := range limits {
+		displayModelName := limit.Group
+		key := displayModelName
+		if fullMode {
+			displayModelName = limit.ModelID
+			key = limit.ModelID
+		}
+		if displayModelName == "" {
+			continue
+		}
+
+		existing, ok := best[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+		if !ok || lowerLimit(limit, existing.limit) {
+			best[key] = displayEntry{limit, displayModelName}
+		}
+	}
+
+	entries := make([]displayEntry, len(keys))
+	for i, key := range keys {
+		entries[i] = best[key]
+	}
+	return entries
 }
 
-func displayQuota(cfg *config.Config) {
+// fetchSnapshot fetches a fresh snapshot and caches it. If the server cannot
+// be reached in time, or in offline mode, it returns the cached snapshot
+// instead and reports it as stale.
+func fetchSnapshot(ctx context.Context, cfg *config.Config) (snap *quotasense.Snapshot, stale bool, ok bool) {
+	if offlineMode {
+		snap, err := loadSnapshotCache()
+		if err != nil {
+			errorColor.Printf("No cached snapshot available: %v\n", err)
+			return nil, false, false
+		}
+		return snap, true, true
+	}
 	if cfg == nil {
-		return
+		return nil, false, false
+	}
+
+	client, err := newClient(cfg)
+	if err != nil {
+		errorColor.Printf("Error: %v\n", err)
+		return nil, false, false
 	}
-	client := api.NewClient(cfg)
 	fmt.Println("Fetching usage information...")
 
-	files, err := client.FetchUsage()
+	snap, err = client.Snapshot(ctx, quotasense.Filter{})
 	if err != nil {
+		if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			errorColor.Printf("Interrupted: %v\n", ctx.Err())
+			return nil, false, false
+		}
 		errorColor.Printf("Error fetching usage: %v\n", err)
-		return
+		cached, cacheErr := loadSnapshotCache()
+		if cacheErr != nil {
+			return nil, false, false
+		}
+		return cached, true, true
+	}
+
+	if ctx.Err() == nil {
+		saveSnapshotCache(snap)
+		return snap, false, true
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fillTimedOut(ctx, snap)
+	}
+	return snap, false, true
+}
+
+// fillTimedOut replaces accounts that did not finish before the --timeout
+// deadline with their cached data, if any. The snapshot is then reported as
+// stale as of the oldest cached data used.
+func fillTimedOut(ctx context.Context, snap *quotasense.Snapshot) (*quotasense.Snapshot, bool, bool) {
+	cached, err := loadSnapshotCache()
+	if err != nil {
+		return snap, false, true
+	}
+	byID := make(map[string]quotasense.AccountQuota, len(cached.Accounts))
+	for _, res := range cached.Accounts {
+		if res.Err == nil {
+			byID[res.Account.ID] = res
+		}
+	}
+
+	stale := false
+	for i, res := range snap.Accounts {
+		old, ok := byID[res.Account.ID]
+		if !ok || res.Err == nil || !errors.Is(res.Err, ctx.Err()) {
+			continue
+		}
+		old.Account = res.Account
+		snap.Accounts[i] = old
+		if !stale || old.FetchedAt.Before(snap.FetchedAt) {
+			snap.FetchedAt = old.FetchedAt
+		}
+		stale = true
 	}
+	return snap, stale, true
+}
 
-	var wg sync.WaitGroup
+// printStaleBanner warns that the quota shown comes from the cache. Reset
+// countdowns are still computed against the current time.
+func printStaleBanner(fetchedAt time.Time) {
+	fmt.Println()
+	color.New(color.FgYellow, color.Bold).Printf("⚠ Offline: showing cached data, stale as of %s (%s ago)\n",
+		fetchedAt.Local().Format("2006-01-02 15:04"), utils.FormatDuration(time.Since(fetchedAt)))
+}
+
+func displayQuota(ctx context.Context, cfg *config.Config) {
+	snap, stale, ok := fetchSnapshot(ctx, cfg)
+	if !ok {
+		return
+	}
+	if stale {
+		printStaleBanner(snap.FetchedAt)
+	}
 
 	fmt.Println()
 	if fullMode {
@@ -96,89 +266,64 @@ func displayQuota(cfg *config.Config) {
 		headerColor.Println(strings.Repeat("-", 115))
 	}
 
-	results := make([]accountResult, len(files))
-
-	for i, file := range files {
-		wg.Add(1)
-		go func(idx int, f models.AuthFile) {
-			defer wg.Done()
-			limits, err := client.FetchQuota(f)
-			res := accountResult{
-				file: f,
-				err:  err,
+	// On interrupt, keep only the accounts that finished before cancellation.
+	results := snap.Accounts
+	pending := 0
+	if ctx.Err() != nil {
+		completed := make([]quotasense.AccountQuota, 0, len(results))
+		for _, res := range results {
+			if res.Err != nil && errors.Is(res.Err, ctx.Err()) {
+				pending++
+				continue
 			}
-			if err == nil {
-				bestInGroup := make(map[string]displayEntry)
-				for modelName, limit := range limits {
-					displayModelName := utils.GetDisplayModelName(modelName, f.Provider, fullMode)
-					if displayModelName == "" {
-						continue
-					}
-
-					key := displayModelName
-					if fullMode {
-						key = modelName
-					}
-
-					if existing, ok := bestInGroup[key]; !ok || limit.RemainingFraction < existing.limit.RemainingFraction {
-						bestInGroup[key] = displayEntry{limit, displayModelName}
-					}
-				}
-				res.bestInGroup = bestInGroup
-			}
-			results[idx] = res
-		}(i, file)
+			completed = append(completed, res)
+		}
+		results = completed
 	}
-	wg.Wait()
 
 	sort.SliceStable(results, func(i, j int) bool {
-		return !results[i].file.Disabled && results[j].file.Disabled
+		return !results[i].Account.Disabled && results[j].Account.Disabled
 	})
 
 	for _, res := range results {
-		f := res.file
-		err := res.err
-		bestInGroup := res.bestInGroup
-
-		if err != nil {
-			if f.Disabled {
-				disabledColor := color.New(color.FgHiBlack)
-				emailStr := f.Email
-				if !strings.Contains(emailStr, "(disabled)") {
-					emailStr += " (disabled)"
-				}
-				disabledColor.Printf("%-40s | ", emailStr)
-				disabledColor.Printf("%-15s | ", f.Provider)
-				disabledColor.Printf("%-10s | ", "Disabled")
-				if fullMode {
-					disabledColor.Printf("%-15s | %-25s | %-20s\n", "-", "-", "-")
-				} else {
-					disabledColor.Printf("%-15s | %-20s\n", "-", "-")
-				}
-			}
-			continue
-		}
+		f := res.Account
+		err := res.Err
+		entries := bestInGroup(res.Limits)
 
-		if len(bestInGroup) == 0 && f.Disabled {
-			disabledColor := color.New(color.FgHiBlack)
+		if f.Disabled && (err != nil || len(entries) == 0) {
 			emailStr := f.Email
 			if !strings.Contains(emailStr, "(disabled)") {
 				emailStr += " (disabled)"
 			}
-			disabledColor.Printf("%-40s | ", emailStr)
-			disabledColor.Printf("%-15s | ", f.Provider)
-			disabledColor.Printf("%-10s | ", "Disabled")
-			if fullMode {
-				disabledColor.Printf("%-15s | %-25s | %-20s\n", "-", "-", "-")
+			printStatusRow(color.New(color.FgHiBlack), emailStr, f.Provider, "Disabled", "-")
+			continue
+		}
+
+		if err != nil {
+			if errors.Is(err, quotasense.ErrUnsupportedProvider) {
+				printStatusRow(color.New(color.FgYellow), f.Email, f.Provider, "-", describeFetchError(err))
 			} else {
-				disabledColor.Printf("%-15s | %-20s\n", "-", "-")
+				printStatusRow(errorColor, f.Email, f.Provider, "Error", describeFetchError(err))
+			}
+			if showErrors || verboseMode {
+				color.New(color.FgHiBlack).Printf("  ↳ %s\n", describeFetchErrorDetail(err))
 			}
 			continue
 		}
 
-		for _, entry := range bestInGroup {
-			remainingStr := strings.TrimSuffix(entry.limit.Remaining, "%")
-			remainingVal, err := strconv.Atoi(remainingStr)
+		for _, entry := range entries {
+			remainingVal := int(entry.limit.RemainingFraction * 100)
+			remainingText := fmt.Sprintf("%d%%", remainingVal)
+			isPercentage := entry.limit.Credits == nil
+			if !isPercentage {
+				remainingText = formatCredits(entry.limit.Credits)
+			}
+			if entry.limit.Blocked {
+				remainingText = "Blocked"
+				remainingVal, isPercentage = 0, true
+			} else if entry.limit.QuotaUnknown {
+				remainingText, isPercentage = "-", false
+			}
 
 			var quotaColor *color.Color
 			var rowColor *color.Color
@@ -190,7 +335,7 @@ func displayQuota(cfg *config.Config) {
 				modelColor = rowColor
 			} else {
 				rowColor = color.New(color.FgWhite)
-				if err == nil {
+				if isPercentage {
 					quotaColor = utils.GetQuotaColor(remainingVal)
 					if remainingVal == 0 {
 						modelColor = color.New(color.FgRed, color.Bold)
@@ -203,7 +348,7 @@ func displayQuota(cfg *config.Config) {
 				}
 			}
 
-			resetStr := utils.GetResetString(entry.limit.ResetTime)
+			resetStr := utils.FormatResetIn(entry.limit.ResetAt)
 
 			emailStr := f.Email
 			if f.Disabled && !strings.Contains(emailStr, "(disabled)") {
@@ -212,7 +357,7 @@ func displayQuota(cfg *config.Config) {
 
 			rowColor.Printf("%-40s | ", emailStr)
 			rowColor.Printf("%-15s | ", f.Provider)
-			quotaColor.Printf("%-10s | ", entry.limit.Remaining)
+			quotaColor.Printf("%-10s | ", remainingText)
 			if fullMode {
 				rowColor.Printf("%-15s | ", resetStr)
 				modelColor.Printf("%-25s | ", entry.limit.DisplayName)
@@ -223,6 +368,50 @@ func displayQuota(cfg *config.Config) {
 			}
 		}
 	}
+
+	if verboseMode {
+		printLatencies(results)
+	}
+
+	if pending > 0 {
+		fmt.Println()
+		errorColor.Printf("Interrupted: %d of %d accounts did not finish (%v)\n", pending, len(snap.Accounts), ctx.Err())
+	}
+}
+
+// printStatusRow prints a row without quota data, such as a disabled or
+// failed account, with the reason in the model column.
+func printStatusRow(c *color.Color, email, provider, remaining, reason string) {
+	c.Printf("%-40s | ", email)
+	c.Printf("%-15s | ", provider)
+	c.Printf("%-10s | ", remaining)
+	if fullMode {
+		c.Printf("%-15s | %-25s | %-20s\n", "-", "-", reason)
+	} else {
+		c.Printf("%-15s | %-20s\n", "-", reason)
+	}
+}
+
+// printLatencies lists how long each account's quota fetch took, slowest first.
+func printLatencies(results []quotasense.AccountQuota) {
+	sorted := make([]quotasense.AccountQuota, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Latency > sorted[j].Latency
+	})
+
+	fmt.Println()
+	headerColor.Printf("%-40s | %-15s | %-10s\n", "Account (Email)", "Provider", "Latency")
+	headerColor.Println(strings.Repeat("-", 71))
+	for _, res := range sorted {
+		latency := "-"
+		if res.Cached {
+			latency = "cached"
+		} else if res.Latency > 0 {
+			latency = res.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Printf("%-40s | %-15s | %-10s\n", res.Account.Email, res.Account.Provider, latency)
+	}
 }
 
 func Execute() {
@@ -234,4 +423,13 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().BoolVarP(&fullMode, "full", "f", false, "Display all available models")
+	rootCmd.Flags().BoolVarP(&verboseMode, "verbose", "v", false, "Show per-account fetch latency and error details")
+	rootCmd.Flags().BoolVar(&offlineMode, "offline", false, "Show the last cached snapshot without contacting the server")
+	rootCmd.Flags().BoolVar(&showErrors, "show-errors", false, "Show the upstream status and response snippet for failed accounts")
+	// The cache flags are persistent because every command that fetches
+	// quota (accounts, usage, models, enforce) goes through the same cache.
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Fetch fresh quota without reading or writing the response cache")
+	rootCmd.PersistentFlags().DurationVar(&maxAge, "max-age", 0, "Reuse cached quota up to this age (e.g. 10s), overriding cache_ttl_seconds")
+	rootCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 0, fmt.Sprintf("Number of accounts to fetch in parallel (default %d)", quotasense.DefaultConcurrency))
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Overall deadline for the command (e.g. 30s); 0 means no limit")
 }
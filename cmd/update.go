@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -18,54 +17,193 @@ import (
 const repoOwner = "quaywin"
 const repoName = "quota-sense-cli"
 
+// githubAPIURL is the GitHub API base URL, replaced in tests.
+var githubAPIURL = "https://api.github.com"
+
 type releaseInfo struct {
 	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
 	Assets  []struct {
 		Name               string `json:"name"`
 		BrowserDownloadURL string `json:"browser_download_url"`
 	} `json:"assets"`
 }
 
+var (
+	updateRollback bool
+	updateVersion  string
+)
+
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update QuotaSense CLI to the latest version",
+	Long: `Downloads, verifies and installs the latest release, or the release given
+with --version (which may be older than the current one). Only releases
+published with a signed SHA256SUMS file can be installed; older releases must
+be downloaded manually. The replaced binary is kept so that --rollback can
+restore it, and a new binary that fails to run is rolled back automatically.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Checking for updates...")
-		latest, err := getLatestRelease()
+		if updateRollback {
+			rollbackUpdate()
+			return
+		}
+
+		var release *releaseInfo
+		var err error
+		if updateVersion != "" {
+			fmt.Printf("Looking up release %s...\n", updateVersion)
+			release, err = getRelease(updateVersion)
+		} else {
+			fmt.Println("Checking for updates...")
+			release, err = getLatestRelease()
+		}
 		if err != nil {
 			errorColor.Printf("Error checking for updates: %v\n", err)
 			return
 		}
 
-		if latest.TagName == Version {
-			successColor.Printf("You are already on the latest version (%s)\n", Version)
+		if release.TagName == Version {
+			successColor.Printf("You are already on %s\n", Version)
+			return
+		}
+		if !release.signed() {
+			errorColor.Printf("Release %s has no signed checksums (it predates signed releases), so it cannot be installed with qs update.\n", release.TagName)
+			if release.HTMLURL != "" {
+				fmt.Printf("Download it manually from %s\n", release.HTMLURL)
+			}
+			return
+		}
+		if _, err := updatePublicKey(); err != nil {
+			errorColor.Printf("Cannot update: %v\n", err)
 			return
 		}
 
-		fmt.Printf("New version available: %s (current: %s)\n", latest.TagName, Version)
-		fmt.Print("Do you want to update? (y/n): ")
-		var confirm string
-		fmt.Scanln(&confirm)
-		if strings.ToLower(confirm) != "y" {
+		ctx, cancel := commandContext()
+		defer cancel()
+		question := fmt.Sprintf("Install %s (current: %s)?", release.TagName, Version)
+		if updateVersion == "" {
+			fmt.Printf("New version available: %s (current: %s)\n", release.TagName, Version)
+			question = "Do you want to update?"
+		}
+		if !confirm(ctx, question) {
 			fmt.Println("Update cancelled.")
 			return
 		}
 
-		err = doUpdate(latest)
+		err = doUpdate(release)
 		if err != nil {
 			errorColor.Printf("Update failed: %v\n", err)
 			return
 		}
 
-		successColor.Printf("Successfully updated to %s!\n", latest.TagName)
+		successColor.Printf("Successfully updated to %s!\n", release.TagName)
+		fmt.Println("Run `qs update --rollback` to go back to the previous version.")
 	},
 }
 
+// rollbackUpdate swaps the current binary with the one kept by the last
+// update and reports the restored version.
+func rollbackUpdate() {
+	exePath, err := executablePath()
+	if err != nil {
+		errorColor.Printf("Rollback failed: %v\n", err)
+		return
+	}
+	if err := swapWithPrevious(exePath); err != nil {
+		errorColor.Printf("Rollback failed: %v\n", err)
+		return
+	}
+
+	version, err := selfTest(exePath, "")
+	if err != nil {
+		errorColor.Printf("Rolled back, but the restored binary failed to run: %v\n", err)
+		return
+	}
+	successColor.Printf("Rolled back to %s\n", strings.TrimPrefix(version, "QuotaSense CLI "))
+}
+
+// executablePath returns the path of the running binary with symlinks
+// resolved, so that updates replace the real file.
+func executablePath() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exePath)
+}
+
+// assetURL returns the download URL of the named asset, or "" if the
+// release has none.
+func (r *releaseInfo) assetURL(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadURL
+		}
+	}
+	return ""
+}
+
+// signed reports whether the release publishes a signed checksum file, which
+// qs update requires.
+func (r *releaseInfo) signed() bool {
+	return r.assetURL(checksumsAsset) != "" && r.assetURL(signatureAsset) != ""
+}
+
 func getLatestRelease() (*releaseInfo, error) {
+	var release releaseInfo
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", githubAPIURL, repoOwner, repoName)
+	if _, err := githubGet(url, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// getRelease finds a release by tag in the repository's releases list,
+// following its pagination. The leading "v" is optional.
+func getRelease(tag string) (*releaseInfo, error) {
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", githubAPIURL, repoOwner, repoName)
+	for url != "" {
+		var releases []releaseInfo
+		header, err := githubGet(url, &releases)
+		if err != nil {
+			return nil, err
+		}
+		for i := range releases {
+			if releases[i].TagName == tag {
+				return &releases[i], nil
+			}
+		}
+		url = nextPageURL(header)
+	}
+	return nil, fmt.Errorf("release %s not found", tag)
+}
+
+// nextPageURL returns the rel="next" URL of a GitHub Link header, or "" on
+// the last page.
+func nextPageURL(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		url, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(url), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// githubGet decodes a GitHub API response into v and returns its headers.
+func githubGet(url string, v any) (http.Header, error) {
 	client := &http.Client{
 		Timeout: 3 * time.Second,
 	}
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", repoOwner, repoName)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -82,12 +220,7 @@ func getLatestRelease() (*releaseInfo, error) {
 		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
-	var release releaseInfo
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, err
-	}
-
-	return &release, nil
+	return resp.Header, json.NewDecoder(resp.Body).Decode(v)
 }
 
 func doUpdate(release *releaseInfo) error {
@@ -101,10 +234,11 @@ func doUpdate(release *releaseInfo) error {
 
 	// Format expected asset name: qs_v0.1.0_darwin_arm64.tar.gz
 	assetPattern := fmt.Sprintf("qs_%s_%s_%s", release.TagName, targetOS, targetArch)
-	var downloadURL string
+	var downloadURL, assetName string
 	for _, asset := range release.Assets {
 		if strings.Contains(asset.Name, assetPattern) && strings.HasSuffix(asset.Name, extension) {
 			downloadURL = asset.BrowserDownloadURL
+			assetName = asset.Name
 			break
 		}
 	}
@@ -113,7 +247,7 @@ func doUpdate(release *releaseInfo) error {
 		return fmt.Errorf("could not find a compatible binary for %s/%s in release %s", targetOS, targetArch, release.TagName)
 	}
 
-	fmt.Println("Downloading latest version...")
+	fmt.Printf("Downloading %s...\n", release.TagName)
 	tmpDir, err := os.MkdirTemp("", "qs-update")
 	if err != nil {
 		return err
@@ -126,7 +260,12 @@ func doUpdate(release *releaseInfo) error {
 		return err
 	}
 
-	exePath, err := os.Executable()
+	fmt.Println("Verifying checksum and signature...")
+	if err := verifyRelease(release, assetName, archivePath, tmpDir); err != nil {
+		return err
+	}
+
+	exePath, err := executablePath()
 	if err != nil {
 		return err
 	}
@@ -138,50 +277,21 @@ func doUpdate(release *releaseInfo) error {
 		binaryName += ".exe"
 	}
 
-	var extractedBinary string
-	if extension == "tar.gz" {
-		cmd := exec.Command("tar", "-xzf", archivePath, "-C", tmpDir)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to extract tar.gz: %v", err)
-		}
-		extractedBinary = filepath.Join(tmpDir, binaryName)
-	} else {
-		// Simple unzip logic for windows if needed, or assume 'unzip' command
-		cmd := exec.Command("tar", "-xf", archivePath, "-C", tmpDir) // Windows 'tar' handles zip too
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to extract zip: %v", err)
-		}
-		extractedBinary = filepath.Join(tmpDir, binaryName)
+	extractedBinary, err := extractBinary(archivePath, binaryName, tmpDir)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", extension, err)
 	}
 
-	if _, err := os.Stat(extractedBinary); os.IsNotExist(err) {
-		return fmt.Errorf("extracted binary not found in archive")
+	if err := installBinary(extractedBinary, exePath); err != nil {
+		return err
 	}
 
-	// Replace current binary
-	if targetOS == "windows" {
-		// Windows specific: can't replace running exe
-		oldExe := exePath + ".old"
-		_ = os.Remove(oldExe)
-		if err := os.Rename(exePath, oldExe); err != nil {
-			return err
-		}
-		if err := moveFile(extractedBinary, exePath); err != nil {
-			return err
-		}
-		fmt.Println("Note: On Windows, you might need to manually delete the .old file.")
-	} else {
-		// Unix: use Rename to replace the binary
-		if err := os.Rename(extractedBinary, exePath); err != nil {
-			// If rename fails (e.g. cross-device), try moving via copy
-			if err := moveFile(extractedBinary, exePath); err != nil {
-				return err
-			}
-		}
-		// Ensure executable permissions
-		if err := os.Chmod(exePath, 0755); err != nil {
-			return err
+	fmt.Println("Checking the new binary...")
+	if _, err := selfTest(exePath, release.TagName); err != nil {
+		if restoreErr := restorePrevious(exePath); restoreErr != nil {
+			return fmt.Errorf("self-test failed: %v; restoring the previous binary also failed: %v", err, restoreErr)
 		}
+		return fmt.Errorf("self-test failed, previous version restored: %w", err)
 	}
 
 	return nil
@@ -235,5 +345,8 @@ func moveFile(src, dst string) error {
 }
 
 func init() {
+	updateCmd.Flags().BoolVar(&updateRollback, "rollback", false, "Restore the version replaced by the last update")
+	updateCmd.Flags().StringVar(&updateVersion, "version", "", "Install this release tag (e.g. v0.4.0); it must have signed checksums")
+	updateCmd.MarkFlagsMutuallyExclusive("rollback", "version")
 	rootCmd.AddCommand(updateCmd)
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected string
+	}{
+		{"", ""},
+		{`<https://api.github.com/repositories/1/releases?page=2>; rel="next", <https://api.github.com/repositories/1/releases?page=5>; rel="last"`,
+			"https://api.github.com/repositories/1/releases?page=2"},
+		{`<https://api.github.com/repositories/1/releases?page=1>; rel="prev", <https://api.github.com/repositories/1/releases?page=1>; rel="first"`, ""},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		if test.link != "" {
+			header.Set("Link", test.link)
+		}
+		if got := nextPageURL(header); got != test.expected {
+			t.Errorf("nextPageURL(%q) = %q; want %q", test.link, got, test.expected)
+		}
+	}
+}
+
+func TestGetReleasePaginates(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/releases?page=2>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[{"tag_name": "v0.9.0"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"tag_name": "v0.3.1", "assets": [{"name": "SHA256SUMS"}]}]`)
+	}))
+	defer srv.Close()
+	defer func(old string) { githubAPIURL = old }(githubAPIURL)
+	githubAPIURL = srv.URL
+
+	release, err := getRelease("0.3.1")
+	if err != nil {
+		t.Fatalf("getRelease returned error: %v", err)
+	}
+	if release.TagName != "v0.3.1" || release.signed() {
+		t.Errorf("getRelease = %+v; want unsigned v0.3.1", release)
+	}
+	if _, err := getRelease("v0.0.1"); err == nil {
+		t.Error("getRelease found a release that does not exist")
+	}
+}
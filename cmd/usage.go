@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/quaywin/quota-sense-cli/internal/utils"
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+	"github.com/spf13/cobra"
+)
+
+// Breakdowns accepted by --by, in display order.
+var usageBreakdowns = []string{"key", "model", "day", "account"}
+
+var (
+	usageBy      []string
+	usageSince   time.Duration
+	usageNoQuota bool
+)
+
+// usageRow is the aggregate of the requests sharing one breakdown value.
+type usageRow struct {
+	Name         string `json:"name"`
+	Provider     string `json:"provider,omitempty"`
+	Requests     int64  `json:"requests"`
+	Failures     int64  `json:"failures"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+	TotalTokens  int64  `json:"total_tokens"`
+	// Remaining is the account's lowest remaining quota fraction, set only
+	// in the account breakdown.
+	Remaining *float64 `json:"remaining_fraction,omitempty"`
+
+	accountID string
+}
+
+type usageReport struct {
+	From   time.Time             `json:"from,omitzero"`
+	To     time.Time             `json:"to,omitzero"`
+	Totals usageRow              `json:"totals"`
+	By     map[string][]usageRow `json:"by"`
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show request counts, tokens and failures from the server's usage statistics",
+	Long: `Shows the management server's request usage statistics, broken down by
+client API key, model, day and account. The account breakdown includes each
+account's lowest remaining quota, so heavy consumers can be matched with
+drained accounts. Statistics cover the time since the server last started.`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: checkOutputFormat,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, by := range usageBy {
+			if !slices.Contains(usageBreakdowns, by) {
+				errorColor.Printf("Error: unknown breakdown %q (want %s)\n", by, strings.Join(usageBreakdowns, ", "))
+				os.Exit(1)
+			}
+		}
+
+		ctx, cancel := commandContext()
+		defer cancel()
+
+		_, client := mustLoadClient()
+		records, err := client.UsageRecords(ctx)
+		if err != nil {
+			errorColor.Printf("Error fetching usage statistics: %v\n", err)
+			os.Exit(1)
+		}
+		if usageSince > 0 {
+			cutoff := time.Now().Add(-usageSince)
+			records = slices.DeleteFunc(records, func(r quotasense.UsageRecord) bool {
+				return r.Time.Before(cutoff)
+			})
+		}
+
+		report := usageReport{By: make(map[string][]usageRow)}
+		if len(records) > 0 {
+			report.From, report.To = records[0].Time, records[len(records)-1].Time
+		}
+		for _, r := range records {
+			report.Totals.add(r)
+		}
+		report.Totals.Name = "total"
+
+		for _, by := range usageBy {
+			switch by {
+			case "key":
+				report.By[by] = aggregateUsage(records, func(r quotasense.UsageRecord) string { return maskAPIKey(r.APIKey) }, byTokens)
+			case "model":
+				report.By[by] = aggregateUsage(records, func(r quotasense.UsageRecord) string { return r.Model }, byTokens)
+			case "day":
+				report.By[by] = aggregateUsage(records, func(r quotasense.UsageRecord) string { return r.Time.Local().Format("2006-01-02") }, byName)
+			case "account":
+				report.By[by] = accountUsage(ctx, client, records)
+			}
+		}
+
+		if outputFormat == outputJSON {
+			printJSON(report)
+			return
+		}
+		printUsageReport(report)
+	},
+}
+
+func (row *usageRow) add(r quotasense.UsageRecord) {
+	row.Requests++
+	if r.Failed {
+		row.Failures++
+	}
+	row.InputTokens += r.InputTokens
+	row.OutputTokens += r.OutputTokens
+	row.TotalTokens += r.TotalTokens
+}
+
+func byTokens(a, b usageRow) bool { return a.TotalTokens > b.TotalTokens }
+func byName(a, b usageRow) bool   { return a.Name < b.Name }
+
+// aggregateUsage sums records by the key returned from keyOf.
+func aggregateUsage(records []quotasense.UsageRecord, keyOf func(quotasense.UsageRecord) string, less func(a, b usageRow) bool) []usageRow {
+	index := make(map[string]int)
+	var rows []usageRow
+	for _, r := range records {
+		key := keyOf(r)
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, usageRow{Name: key})
+		}
+		rows[i].add(r)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+	return rows
+}
+
+// accountUsage sums records per account, matched by auth index or else by
+// source email, and adds each account's lowest remaining quota unless
+// --no-quota is set. Records that match no account keep their source.
+func accountUsage(ctx context.Context, client *quotasense.Client, records []quotasense.UsageRecord) []usageRow {
+	accounts, err := client.ListAccounts(ctx)
+	if err != nil {
+		errorColor.Fprintf(os.Stderr, "Could not list accounts to join usage: %v\n", err)
+	}
+	byIndex := make(map[string]quotasense.Account)
+	byEmail := make(map[string]quotasense.Account)
+	for _, a := range accounts {
+		byIndex[a.AuthIndex] = a
+		if a.Email != "" {
+			byEmail[a.Email] = a
+		}
+	}
+	resolve := func(r quotasense.UsageRecord) (quotasense.Account, bool) {
+		if a, ok := byIndex[r.AuthIndex]; ok && r.AuthIndex != "" {
+			return a, true
+		}
+		a, ok := byEmail[r.Source]
+		return a, ok
+	}
+
+	rows := aggregateUsage(records, func(r quotasense.UsageRecord) string {
+		if a, ok := resolve(r); ok {
+			return a.ID
+		}
+		if r.Source != "" {
+			return r.Source
+		}
+		return "unknown"
+	}, byTokens)
+
+	var ids []string
+	accountByID := make(map[string]quotasense.Account)
+	for _, a := range accounts {
+		accountByID[a.ID] = a
+	}
+	for i, row := range rows {
+		if a, ok := accountByID[row.Name]; ok {
+			rows[i].Name, rows[i].Provider, rows[i].accountID = a.Email, a.Provider, a.ID
+			ids = append(ids, a.ID)
+		}
+	}
+	if usageNoQuota || len(ids) == 0 {
+		return rows
+	}
+
+	snap, err := client.Snapshot(ctx, quotasense.Filter{Accounts: ids})
+	if err != nil {
+		errorColor.Fprintf(os.Stderr, "Could not fetch quota to join usage: %v\n", err)
+		return rows
+	}
+	remaining := make(map[string]float64)
+	for _, res := range snap.Accounts {
+		if lowest, ok := lowestRemaining(res.Limits); ok && res.Err == nil {
+			remaining[res.Account.ID] = lowest
+		}
+	}
+	for i, row := range rows {
+		if v, ok := remaining[row.accountID]; ok && row.accountID != "" {
+			rows[i].Remaining = &v
+		}
+	}
+	return rows
+}
+
+// lowestRemaining returns the lowest remaining fraction among the limits
+// shown in the summary view.
+func lowestRemaining(limits []quotasense.Limit) (float64, bool) {
+	lowest, found := 1.0, false
+	for _, l := range limits {
+		if l.Group == "" || l.Credits != nil || l.QuotaUnknown {
+			continue
+		}
+		v := l.RemainingFraction
+		if l.Blocked {
+			v = 0
+		}
+		lowest, found = min(lowest, v), true
+	}
+	return lowest, found
+}
+
+// maskAPIKey hides all but the ends of a client API key.
+func maskAPIKey(key string) string {
+	runes := []rune(key)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + "…" + string(runes[len(runes)-4:])
+}
+
+func printUsageReport(report usageReport) {
+	if report.Totals.Requests == 0 {
+		fmt.Println("No requests recorded.")
+		return
+	}
+	headerColor.Printf("%s requests, %s failed, %s tokens", utils.FormatCount(report.Totals.Requests),
+		utils.FormatCount(report.Totals.Failures), utils.FormatCount(report.Totals.TotalTokens))
+	fmt.Printf(" (%s – %s)\n", report.From.Local().Format("2006-01-02 15:04"), report.To.Local().Format("2006-01-02 15:04"))
+
+	titles := map[string]string{"key": "API Key", "model": "Model", "day": "Day", "account": "Account (Email)"}
+	for _, by := range usageBy {
+		rows := report.By[by]
+		withQuota := by == "account" && !usageNoQuota
+
+		fmt.Println()
+		header := fmt.Sprintf("%-40s | %-10s | %-8s | %-12s | %-12s | %-12s", titles[by], "Requests", "Failed", "Input", "Output", "Total")
+		if withQuota {
+			header += fmt.Sprintf(" | %-10s", "Remaining")
+		}
+		headerColor.Println(header)
+		headerColor.Println(strings.Repeat("-", len([]rune(header))))
+
+		for _, row := range rows {
+			rowColor := color.New(color.FgWhite)
+			if row.Failures > 0 {
+				rowColor = color.New(color.FgYellow)
+			}
+			rowColor.Printf("%-40s | %-10s | %-8s | %-12s | %-12s | %-12s", row.Name, utils.FormatCount(row.Requests), utils.FormatCount(row.Failures),
+				utils.FormatCount(row.InputTokens), utils.FormatCount(row.OutputTokens), utils.FormatCount(row.TotalTokens))
+			if withQuota {
+				if row.Remaining == nil {
+					rowColor.Printf(" | %-10s", "-")
+				} else {
+					pct := int(*row.Remaining * 100)
+					rowColor.Print(" | ")
+					utils.GetQuotaColor(pct).Printf("%-10s", fmt.Sprintf("%d%%", pct))
+				}
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func init() {
+	addOutputFlag(usageCmd)
+	usageCmd.Flags().StringSliceVar(&usageBy, "by", usageBreakdowns, "Breakdowns to show: key, model, day, account")
+	usageCmd.Flags().DurationVar(&usageSince, "since", 0, "Only count requests within this duration (e.g. 24h)")
+	usageCmd.Flags().BoolVar(&usageNoQuota, "no-quota", false, "Skip fetching quota for the account breakdown")
+	rootCmd.AddCommand(usageCmd)
+}
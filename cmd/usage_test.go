@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/quaywin/quota-sense-cli/pkg/quotasense"
+)
+
+func TestAggregateUsage(t *testing.T) {
+	day := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	records := []quotasense.UsageRecord{
+		{Time: day, Model: "gpt-5", InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		{Time: day, Model: "gemini-2.5-pro", TotalTokens: 100},
+		{Time: day.Add(time.Hour), Model: "gpt-5", TotalTokens: 20, Failed: true},
+	}
+
+	rows := aggregateUsage(records, func(r quotasense.UsageRecord) string { return r.Model }, byTokens)
+	if len(rows) != 2 {
+		t.Fatalf("aggregateUsage returned %d rows; want 2", len(rows))
+	}
+	if rows[0].Name != "gemini-2.5-pro" || rows[0].TotalTokens != 100 {
+		t.Errorf("rows[0] = %+v; want gemini-2.5-pro with 100 tokens", rows[0])
+	}
+	gpt := rows[1]
+	if gpt.Requests != 2 || gpt.Failures != 1 || gpt.InputTokens != 10 || gpt.OutputTokens != 5 || gpt.TotalTokens != 35 {
+		t.Errorf("gpt-5 row = %+v", gpt)
+	}
+}
+
+func TestLowestRemaining(t *testing.T) {
+	limits := []quotasense.Limit{
+		{ModelID: "5h", Group: "5h", RemainingFraction: 0.6},
+		{ModelID: "weekly", Group: "weekly", RemainingFraction: 0.4},
+		{ModelID: "hidden", RemainingFraction: 0},
+		{ModelID: "credits", Group: "credits", Credits: &quotasense.Credits{}},
+	}
+	if got, ok := lowestRemaining(limits); !ok || got != 0.4 {
+		t.Errorf("lowestRemaining = %v, %v; want 0.4, true", got, ok)
+	}
+
+	limits[0].Blocked = true
+	if got, _ := lowestRemaining(limits); got != 0 {
+		t.Errorf("lowestRemaining with a blocked limit = %v; want 0", got)
+	}
+	if _, ok := lowestRemaining(nil); ok {
+		t.Errorf("lowestRemaining(nil) reported a value")
+	}
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	tests := map[string]string{
+		"sk-abcdef123456": "sk-a…3456",
+		"short":           "*****",
+		"":                "",
+		"ключ-доступа-42": "ключ…а-42",
+		"日本語キー":           "*****",
+	}
+	for key, expected := range tests {
+		got := maskAPIKey(key)
+		if got != expected {
+			t.Errorf("maskAPIKey(%q) = %q; want %q", key, got, expected)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("maskAPIKey(%q) = %q is not valid UTF-8", key, got)
+		}
+	}
+}
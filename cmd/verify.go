@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// embeddedKeys holds cmd/keys, where the maintainers commit the minisign
+// public key that release checksums are signed with, as keys/minisign.pub.
+//
+//go:embed keys
+var embeddedKeys embed.FS
+
+// releaseKeys is where the release key is read from; tests replace it.
+var releaseKeys fs.FS = embeddedKeys
+
+// errNoTrustedKey is returned when the build has no release key, in which
+// case nothing can be verified and updates are refused.
+var errNoTrustedKey = errors.New("no trusted key configured: this build cannot verify releases; download updates manually")
+
+// updatePublicKey returns the base64 key release checksums must be signed
+// with, from the last line of keys/minisign.pub.
+func updatePublicKey() (string, error) {
+	data, err := fs.ReadFile(releaseKeys, "keys/minisign.pub")
+	if err != nil {
+		return "", errNoTrustedKey
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	key := strings.TrimSpace(lines[len(lines)-1])
+	if key == "" {
+		return "", errNoTrustedKey
+	}
+	return key, nil
+}
+
+const (
+	checksumsAsset = "SHA256SUMS"
+	signatureAsset = "SHA256SUMS.minisig"
+)
+
+// errVerification marks a release that failed checksum or signature checks.
+var errVerification = errors.New("release verification failed")
+
+// verifyMinisign checks a minisign signature of data against a base64
+// public key, accepting both legacy and BLAKE2b-prehashed signatures. The
+// trusted comment is verified too, since minisign signs it separately.
+func verifyMinisign(publicKey string, data, sigFile []byte) error {
+	pk, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(pk) != 42 || string(pk[:2]) != "Ed" {
+		return fmt.Errorf("%w: invalid minisign public key", errVerification)
+	}
+	keyID, key := pk[2:10], ed25519.PublicKey(pk[10:])
+
+	lines := strings.Split(strings.ReplaceAll(string(sigFile), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("%w: malformed signature file", errVerification)
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 74 {
+		return fmt.Errorf("%w: malformed signature", errVerification)
+	}
+	if !bytes.Equal(sig[2:10], keyID) {
+		return fmt.Errorf("%w: signed with a different key", errVerification)
+	}
+
+	message := data
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	default:
+		return fmt.Errorf("%w: unsupported signature algorithm %q", errVerification, sig[:2])
+	}
+	if !ed25519.Verify(key, message, sig[10:]) {
+		return fmt.Errorf("%w: invalid signature", errVerification)
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed trusted comment signature", errVerification)
+	}
+	signed := append(bytes.Clone(sig[10:]), trustedComment...)
+	if !ed25519.Verify(key, signed, globalSig) {
+		return fmt.Errorf("%w: invalid trusted comment signature", errVerification)
+	}
+	return nil
+}
+
+// expectedChecksum finds the SHA-256 for name in a sha256sum-style file.
+func expectedChecksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s is not listed in %s", errVerification, name, checksumsAsset)
+}
+
+// verifyFileChecksum compares the SHA-256 of the file at path with want.
+func verifyFileChecksum(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("%w: checksum mismatch (got %s, want %s)", errVerification, got, want)
+	}
+	return nil
+}
+
+// verifyRelease downloads the release's signed checksum file and checks the
+// archive at archivePath against it. Releases without a checksum file or
+// signature are refused.
+func verifyRelease(release *releaseInfo, assetName, archivePath, tmpDir string) error {
+	sumsURL, sigURL := release.assetURL(checksumsAsset), release.assetURL(signatureAsset)
+	if sumsURL == "" || sigURL == "" {
+		return fmt.Errorf("%w: release %s has no %s or %s", errVerification, release.TagName, checksumsAsset, signatureAsset)
+	}
+
+	sumsPath := filepath.Join(tmpDir, checksumsAsset)
+	sigPath := filepath.Join(tmpDir, signatureAsset)
+	if err := downloadFile(sumsPath, sumsURL); err != nil {
+		return err
+	}
+	if err := downloadFile(sigPath, sigURL); err != nil {
+		return err
+	}
+	sums, err := os.ReadFile(sumsPath)
+	if err != nil {
+		return err
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := updatePublicKey()
+	if err != nil {
+		return err
+	}
+	if err := verifyMinisign(publicKey, sums, sig); err != nil {
+		return err
+	}
+	want, err := expectedChecksum(sums, assetName)
+	if err != nil {
+		return err
+	}
+	return verifyFileChecksum(archivePath, want)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignFixture returns a minisign public key and a signature of data in
+// minisign's file format, prehashed when alg is "ED".
+func minisignFixture(t *testing.T, data []byte, alg, comment string) (string, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey returned error: %v", err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	message := data
+	if alg == "ED" {
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	}
+	sig := append(append([]byte(alg), keyID...), ed25519.Sign(priv, message)...)
+	global := ed25519.Sign(priv, append(sig[10:len(sig):len(sig)], comment...))
+
+	publicKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...))
+	sigFile := fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(sig), comment, base64.StdEncoding.EncodeToString(global))
+	return publicKey, []byte(sigFile)
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	data := []byte("abc123  qs_v1.0.0_linux_amd64.tar.gz\n")
+
+	for _, alg := range []string{"Ed", "ED"} {
+		publicKey, sig := minisignFixture(t, data, alg, "quota-sense-cli v1.0.0")
+		if err := verifyMinisign(publicKey, data, sig); err != nil {
+			t.Errorf("verifyMinisign(%s) returned error: %v", alg, err)
+		}
+		if err := verifyMinisign(publicKey, append(data, '!'), sig); !errors.Is(err, errVerification) {
+			t.Errorf("verifyMinisign(%s) accepted tampered data: %v", alg, err)
+		}
+	}
+
+	publicKey, sig := minisignFixture(t, data, "ED", "v1.0.0")
+	otherKey, _ := minisignFixture(t, data, "ED", "v1.0.0")
+	if err := verifyMinisign(otherKey, data, sig); !errors.Is(err, errVerification) {
+		t.Errorf("verifyMinisign accepted a signature from another key: %v", err)
+	}
+
+	tampered := bytes.Replace(sig, []byte("trusted comment: v1.0.0"), []byte("trusted comment: v9.9.9"), 1)
+	if err := verifyMinisign(publicKey, data, tampered); !errors.Is(err, errVerification) {
+		t.Errorf("verifyMinisign accepted a tampered trusted comment: %v", err)
+	}
+}
+
+func TestUpdatePublicKey(t *testing.T) {
+	if key, err := updatePublicKey(); err == nil {
+		pk, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(pk) != 42 || string(pk[:2]) != "Ed" {
+			t.Fatalf("embedded public key %q is not a minisign public key", key)
+		}
+	} else if !errors.Is(err, errNoTrustedKey) {
+		t.Fatalf("updatePublicKey returned error: %v", err)
+	}
+	defer func(keys fs.FS) { releaseKeys = keys }(releaseKeys)
+
+	releaseKeys = fstest.MapFS{}
+	if _, err := updatePublicKey(); !errors.Is(err, errNoTrustedKey) {
+		t.Errorf("updatePublicKey without a key file = %v; want errNoTrustedKey", err)
+	}
+	releaseKeys = fstest.MapFS{"keys/minisign.pub": {Data: []byte("\n")}}
+	if _, err := updatePublicKey(); !errors.Is(err, errNoTrustedKey) {
+		t.Errorf("updatePublicKey with an empty key file = %v; want errNoTrustedKey", err)
+	}
+
+	publicKey, _ := minisignFixture(t, nil, "ED", "")
+	releaseKeys = fstest.MapFS{"keys/minisign.pub": {Data: []byte("untrusted comment: minisign public key 0807060504030201\n" + publicKey + "\n")}}
+	if key, err := updatePublicKey(); err != nil || key != publicKey {
+		t.Errorf("updatePublicKey() = %q, %v; want %q", key, err, publicKey)
+	}
+}
+
+func TestVerifyReleaseWithoutKey(t *testing.T) {
+	data := []byte("abc123  qs.tar.gz\n")
+	_, sig := minisignFixture(t, data, "ED", "v1.0.0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".minisig") {
+			w.Write(sig)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	defer func(keys fs.FS) { releaseKeys = keys }(releaseKeys)
+	releaseKeys = fstest.MapFS{}
+
+	var release releaseInfo
+	assets := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[
+		{"name":"SHA256SUMS","browser_download_url":"%[1]s/SHA256SUMS"},
+		{"name":"SHA256SUMS.minisig","browser_download_url":"%[1]s/SHA256SUMS.minisig"}
+	]}`, server.URL)
+	if err := json.Unmarshal([]byte(assets), &release); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := verifyRelease(&release, "qs.tar.gz", filepath.Join(dir, "qs.tar.gz"), dir); !errors.Is(err, errNoTrustedKey) {
+		t.Errorf("verifyRelease without a trusted key = %v; want errNoTrustedKey", err)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qs.tar.gz")
+	if err := os.WriteFile(path, []byte("archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("archive"))
+	sums := []byte(fmt.Sprintf("%s  qs_v1_linux_amd64.tar.gz\n%s *qs_v1_windows_amd64.zip\n",
+		hex.EncodeToString(sum[:]), "00"))
+
+	want, err := expectedChecksum(sums, "qs_v1_linux_amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("expectedChecksum returned error: %v", err)
+	}
+	if err := verifyFileChecksum(path, want); err != nil {
+		t.Errorf("verifyFileChecksum returned error: %v", err)
+	}
+
+	if want, _ := expectedChecksum(sums, "qs_v1_windows_amd64.zip"); want != "00" {
+		t.Errorf("expectedChecksum for binary-mode entry = %q; want 00", want)
+	}
+	if err := verifyFileChecksum(path, "00"); !errors.Is(err, errVerification) {
+		t.Errorf("verifyFileChecksum accepted a mismatch: %v", err)
+	}
+	if _, err := expectedChecksum(sums, "qs_v1_darwin_arm64.tar.gz"); !errors.Is(err, errVerification) {
+		t.Errorf("expectedChecksum found an unlisted asset: %v", err)
+	}
+}
@@ -2,10 +2,16 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
-	"strings"
+	"net/url"
+	"sort"
 	"time"
 
 	"github.com/quaywin/quota-sense-cli/internal/config"
@@ -16,251 +22,321 @@ const (
 	googleCloudCodeURL   = "https://daily-cloudcode-pa.googleapis.com/v1internal:fetchAvailableModels"
 	geminiQuotaURL       = "https://cloudcode-pa.googleapis.com/v1internal:retrieveUserQuota"
 	chatgptUsageURL      = "https://chatgpt.com/backend-api/wham/usage"
+	claudeUsageURL       = "https://api.anthropic.com/api/oauth/usage"
 	antigravityUserAgent = "antigravity/1.11.5 darwin/amd64"
 	codexUserAgent       = "codex_cli_rs/0.76.0 (Debian 13.0.0; x86_64) WindowsTerminal"
+	claudeOAuthBeta      = "oauth-2025-04-20"
+
+	DefaultManagementTimeout = 10 * time.Second
+	DefaultQuotaTimeout      = 15 * time.Second
 )
 
 type Client struct {
-	cfg *config.Config
+	cfg     *config.Config
+	baseURL string
+
+	httpClient        *http.Client
+	managementTimeout time.Duration
+	quotaTimeout      time.Duration
+
+	logger        *slog.Logger
+	dumpBodies    bool
+	unsafeLogging bool
 }
 
-func NewClient(cfg *config.Config) *Client {
-	return &Client{cfg: cfg}
+// NewClient creates a client for the management server described by cfg.
+// It fails if the TLS or proxy settings cannot be loaded.
+func NewClient(cfg *config.Config, opts ...Option) (*Client, error) {
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	transport, err := newTransport(cfg, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		cfg:               cfg,
+		baseURL:           baseURL(cfg.ServerURL),
+		httpClient:        &http.Client{Transport: transport},
+		managementTimeout: DefaultManagementTimeout,
+		quotaTimeout:      DefaultQuotaTimeout,
+	}
+	if cfg.ManagementTimeout > 0 {
+		c.managementTimeout = time.Duration(cfg.ManagementTimeout) * time.Second
+	}
+	if cfg.QuotaTimeout > 0 {
+		c.quotaTimeout = time.Duration(cfg.QuotaTimeout) * time.Second
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
-func (c *Client) CheckConnection() error {
-	_, err := c.FetchUsage()
+func (c *Client) CheckConnection(ctx context.Context) error {
+	_, err := c.FetchUsage(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to connect to server or invalid token: %v", err)
+		return fmt.Errorf("failed to connect to server or invalid token: %w", err)
 	}
 	return nil
 }
 
-func (c *Client) FetchUsage() ([]models.AuthFile, error) {
-	url := fmt.Sprintf("%s/v0/management/auth-files", c.cfg.ServerURL)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+c.cfg.ManagementToken)
+func (c *Client) FetchUsage(ctx context.Context) ([]models.AuthFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.managementTimeout)
+	defer cancel()
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	body, err := c.doManagement(ctx, "GET", "/v0/management/auth-files", nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch auth files: %d", resp.StatusCode)
-	}
 
 	var authFilesResponse models.AuthFilesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&authFilesResponse); err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, &authFilesResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode auth files: %w", err)
 	}
 
 	return authFilesResponse.Files, nil
 }
 
-func (c *Client) FetchQuota(file models.AuthFile) (map[string]models.ModelLimit, error) {
-	if file.Provider == "codex" {
-		return c.fetchCodexQuota(file)
-	}
-	return c.fetchGoogleQuota(file)
-}
+// FetchUsageStatistics returns the server's per-request usage statistics.
+func (c *Client) FetchUsageStatistics(ctx context.Context) (*models.UsageStatisticsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.managementTimeout)
+	defer cancel()
 
-func (c *Client) fetchCodexQuota(file models.AuthFile) (map[string]models.ModelLimit, error) {
-	resp, err := c.GetCodexProvider(file)
+	body, err := c.doManagement(ctx, "GET", "/v0/management/usage", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	limits := make(map[string]models.ModelLimit)
-	modelName := resp.PlanType
-	if modelName == "" {
-		modelName = "codex"
+	var stats models.UsageStatisticsResponse
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode usage statistics: %w", err)
 	}
+	return &stats, nil
+}
 
-	// Primary window (5h)
-	primaryRemaining := 100.0 - resp.RateLimit.PrimaryWindow.UsedPercent
-	if primaryRemaining < 0 {
-		primaryRemaining = 0
-	}
-	primaryResetTime := time.Unix(resp.RateLimit.PrimaryWindow.ResetAt, 0).Format(time.RFC3339)
+// SetAuthFileDisabled enables or disables an auth file by name through the
+// management server's status endpoint.
+func (c *Client) SetAuthFileDisabled(ctx context.Context, name string, disabled bool) error {
+	ctx, cancel := context.WithTimeout(ctx, c.managementTimeout)
+	defer cancel()
 
-	limits[modelName] = models.ModelLimit{
-		Remaining:         fmt.Sprintf("%d%%", int(primaryRemaining)),
-		RemainingFraction: primaryRemaining / 100.0,
-		ResetTime:         primaryResetTime,
+	reqBody, err := json.Marshal(models.AuthFileStatusRequest{Name: name, Disabled: disabled})
+	if err != nil {
+		return err
 	}
+	_, err = c.doManagement(ctx, "PATCH", "/v0/management/auth-files/status", reqBody,
+		slog.String("name", c.redactString(name)))
+	return err
+}
 
-	// Secondary window (weekly)
-	if resp.RateLimit.SecondaryWindow != nil {
-		secondaryRemaining := 100.0 - resp.RateLimit.SecondaryWindow.UsedPercent
-		if secondaryRemaining < 0 {
-			secondaryRemaining = 0
-		}
-		secondaryResetTime := time.Unix(resp.RateLimit.SecondaryWindow.ResetAt, 0).Format(time.RFC3339)
-
-		limits[modelName+" (weekly)"] = models.ModelLimit{
-			Remaining:         fmt.Sprintf("%d%%", int(secondaryRemaining)),
-			RemainingFraction: secondaryRemaining / 100.0,
-			ResetTime:         secondaryResetTime,
-		}
-	}
+// UploadAuthFile stores an auth file on the management server under name,
+// replacing any existing file with that name.
+func (c *Client) UploadAuthFile(ctx context.Context, name string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, c.managementTimeout)
+	defer cancel()
 
-	return limits, nil
+	_, err := c.doManagement(ctx, "POST", "/v0/management/auth-files?name="+url.QueryEscape(name), data,
+		slog.String("name", c.redactString(name)))
+	return err
 }
 
-func (c *Client) fetchGoogleQuota(file models.AuthFile) (map[string]models.ModelLimit, error) {
-	proxyURL := fmt.Sprintf("%s/v0/management/api-call", c.cfg.ServerURL)
+// DeleteAuthFile removes the named auth file from the management server.
+func (c *Client) DeleteAuthFile(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.managementTimeout)
+	defer cancel()
 
-	targetURL := googleCloudCodeURL
-	headers := map[string]string{
-		"Authorization": "Bearer $TOKEN$",
-		"Content-Type":  "application/json",
-		"User-Agent":    antigravityUserAgent,
+	_, err := c.doManagement(ctx, "DELETE", "/v0/management/auth-files?name="+url.QueryEscape(name), nil,
+		slog.String("name", c.redactString(name)))
+	return err
+}
+
+// doManagement sends an authenticated request to the management server and
+// returns the response body. Non-2xx responses become a *ManagementError.
+// Extra attrs are added to the debug log entry for the request.
+func (c *Client) doManagement(ctx context.Context, method, endpoint string, reqBody []byte, attrs ...any) ([]byte, error) {
+	var bodyReader io.Reader
+	if reqBody != nil {
+		bodyReader = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.ManagementToken)
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	data := "{}"
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	var respBody []byte
+	if err == nil {
+		respBody, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
 
-	extractProjectID := func() string {
-		projectID := file.ProjectID
-		if projectID == "" && file.Account != "" {
-			if start := strings.Index(file.Account, "("); start != -1 {
-				if end := strings.Index(file.Account, ")"); end != -1 && end > start {
-					projectID = file.Account[start+1 : end]
-				}
-			}
+	if c.debugEnabled() {
+		logAttrs := append([]any{
+			slog.String("method", method),
+			slog.String("url", c.redactURL(req.URL.String())),
+			slog.Duration("duration", time.Since(start)),
+		}, attrs...)
+		if err != nil {
+			logAttrs = append(logAttrs, slog.String("error", c.redactString(err.Error())))
+		} else {
+			logAttrs = append(logAttrs, slog.Int("status", resp.StatusCode), slog.Int("body_bytes", len(respBody)))
 		}
-		return projectID
+		logAttrs = c.logBody(logAttrs, "request_body", reqBody)
+		logAttrs = c.logBody(logAttrs, "response_body", respBody)
+		c.logger.DebugContext(ctx, "management request", logAttrs...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newManagementError(endpoint, resp.StatusCode, respBody)
 	}
+	return respBody, nil
+}
 
-	if file.Provider == "gemini-cli" {
-		targetURL = geminiQuotaURL
-		delete(headers, "User-Agent")
-		data = fmt.Sprintf(`{"project":"%s"}`, extractProjectID())
-	} else if file.Provider == "antigravity" {
-		data = fmt.Sprintf(`{"project":"%s"}`, extractProjectID())
+// FetchQuota fetches the model limits for an auth file using the
+// QuotaProvider registered for its type, sorted by model ID.
+func (c *Client) FetchQuota(ctx context.Context, file models.AuthFile) ([]models.ModelLimit, error) {
+	provider, ok := LookupProvider(file.Provider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedProvider, file.Provider)
 	}
 
-	proxyReqBody := models.ProxyRequest{
-		AuthIndex: file.AuthIndex,
-		Method:    "POST",
-		URL:       targetURL,
-		Header:    headers,
-		Data:      data,
+	body, err := c.fetchProviderBody(ctx, provider, file)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := provider.ParseResponse(file, body)
+	if err != nil {
+		return nil, err
 	}
 
-	jsonData, _ := json.Marshal(proxyReqBody)
-	req, _ := http.NewRequest("POST", proxyURL, bytes.NewBuffer(jsonData))
-	req.Header.Set("Authorization", "Bearer "+c.cfg.ManagementToken)
-	req.Header.Set("Content-Type", "application/json")
+	fetchedAt := time.Now()
+	for i := range limits {
+		limits[i].Provider = file.Provider
+		limits[i].Group = provider.DisplayGroup(limits[i].ModelID)
+		limits[i].FetchedAt = fetchedAt
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ModelID < limits[j].ModelID })
+	return limits, nil
+}
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+// fetchProviderBody sends the provider's request through the api-call proxy
+// and returns the upstream body of a successful response.
+func (c *Client) fetchProviderBody(ctx context.Context, provider QuotaProvider, file models.AuthFile) ([]byte, error) {
+	proxyReqBody, err := provider.BuildRequest(file)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var proxyResp models.ProxyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&proxyResp); err != nil {
+	proxyResp, err := c.apiCall(ctx, provider.Name(), proxyReqBody)
+	if err != nil {
 		return nil, err
 	}
 
 	if proxyResp.StatusCode != 200 {
-		return nil, fmt.Errorf("proxy returned status %d", proxyResp.StatusCode)
+		return nil, &UpstreamError{Provider: provider.Name(), StatusCode: proxyResp.StatusCode, Body: proxyResp.Body}
 	}
 
-	limits := make(map[string]models.ModelLimit)
+	return []byte(proxyResp.Body), nil
+}
 
-	if file.Provider == "gemini-cli" {
-		var geminiResp models.GeminiQuotaResponse
-		if err := json.Unmarshal([]byte(proxyResp.Body), &geminiResp); err != nil {
+// apiCall sends a request through the management server's api-call proxy,
+// which substitutes the account token identified by AuthIndex. Upstream
+// 429/5xx responses are retried according to the provider's retry policy,
+// honoring Retry-After up to the maximum backoff and never sleeping past the
+// context deadline. A longer Retry-After returns the response immediately.
+func (c *Client) apiCall(ctx context.Context, provider string, proxyReqBody models.ProxyRequest) (*models.ProxyResponse, error) {
+	policy := retryPolicyFor(c.cfg, provider)
+
+	for attempt := 1; ; attempt++ {
+		proxyResp, err := c.apiCallOnce(ctx, proxyReqBody)
+
+		var retryAfter time.Duration
+		var mgmtErr *ManagementError
+		var netErr net.Error
+		switch {
+		case err != nil && ctx.Err() != nil:
 			return nil, err
-		}
-		for _, bucket := range geminiResp.Buckets {
-			if bucket.ModelID != "" {
-				limits[bucket.ModelID] = models.ModelLimit{
-					Remaining:         fmt.Sprintf("%d%%", int(bucket.RemainingFraction*100)),
-					RemainingFraction: bucket.RemainingFraction,
-					ResetTime:         bucket.ResetTime,
-				}
+		case errors.As(err, &mgmtErr):
+			if !isRetryableStatus(mgmtErr.StatusCode) {
+				return nil, err
 			}
-		}
-	} else {
-		var googleResp models.FetchAvailableModelsResponse
-		if err := json.Unmarshal([]byte(proxyResp.Body), &googleResp); err != nil {
+		case errors.As(err, &netErr):
+			// Transport failure or per-request timeout; retry.
+		case err != nil:
 			return nil, err
+		case isRetryableStatus(proxyResp.StatusCode):
+			retryAfter = parseRetryAfter(proxyResp.Header, time.Now())
+		default:
+			return proxyResp, nil
 		}
-		for key, model := range googleResp.Models {
-			var remaining float64
-			var resetTime string
-			if model.QuotaInfo != nil {
-				remaining = model.QuotaInfo.RemainingFraction
-				resetTime = model.QuotaInfo.ResetTime
-			}
-			limits[key] = models.ModelLimit{
-				Remaining:         fmt.Sprintf("%d%%", int(remaining*100)),
-				RemainingFraction: remaining,
-				ResetTime:         resetTime,
-				DisplayName:       model.DisplayName,
-			}
+
+		if attempt >= policy.maxAttempts {
+			return proxyResp, err
+		}
+		delay, ok := policy.delay(attempt, retryAfter)
+		if !ok || !sleepWithin(ctx, delay) {
+			return proxyResp, err
 		}
 	}
-
-	return limits, nil
 }
 
-func (c *Client) GetCodexProvider(file models.AuthFile) (*models.CodexUsageResponse, error) {
-	proxyURL := fmt.Sprintf("%s/v0/management/api-call", c.cfg.ServerURL)
-
-	headers := map[string]string{
-		"Authorization":      "Bearer $TOKEN$",
-		"Content-Type":       "application/json",
-		"User-Agent":         codexUserAgent,
-		"Chatgpt-Account-Id": file.IDToken.ChatgptAccountID,
-	}
+// APICall sends an arbitrary request through the api-call proxy once,
+// without retries, and returns the upstream response whatever its status.
+func (c *Client) APICall(ctx context.Context, proxyReqBody models.ProxyRequest) (*models.ProxyResponse, error) {
+	return c.apiCallOnce(ctx, proxyReqBody)
+}
 
-	proxyReqBody := models.ProxyRequest{
-		AuthIndex: file.AuthIndex,
-		Method:    "GET",
-		URL:       chatgptUsageURL,
-		Header:    headers,
-	}
+// apiCallOnce performs a single api-call request.
+func (c *Client) apiCallOnce(ctx context.Context, proxyReqBody models.ProxyRequest) (*models.ProxyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.quotaTimeout)
+	defer cancel()
 
 	jsonData, err := json.Marshal(proxyReqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal proxy request: %v", err)
+		return nil, fmt.Errorf("failed to marshal proxy request: %w", err)
 	}
 
-	req, _ := http.NewRequest("POST", proxyURL, bytes.NewBuffer(jsonData))
-	req.Header.Set("Authorization", "Bearer "+c.cfg.ManagementToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+	body, err := c.doManagement(ctx, "POST", "/v0/management/api-call", jsonData,
+		slog.String("auth_index", proxyReqBody.AuthIndex),
+		slog.String("target_method", proxyReqBody.Method),
+		slog.String("target_url", c.redactURL(proxyReqBody.URL)),
+	)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("management server returned status %d", resp.StatusCode)
-	}
 
 	var proxyResp models.ProxyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&proxyResp); err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, &proxyResp); err != nil {
+		return nil, fmt.Errorf("failed to decode api-call response: %w", err)
 	}
 
-	if proxyResp.StatusCode != 200 {
-		return nil, fmt.Errorf("target API returned status %d", proxyResp.StatusCode)
+	if c.debugEnabled() {
+		c.logger.DebugContext(ctx, "upstream response",
+			slog.String("auth_index", proxyReqBody.AuthIndex),
+			slog.String("target_url", c.redactURL(proxyReqBody.URL)),
+			slog.Int("upstream_status", proxyResp.StatusCode),
+			slog.Int("body_bytes", len(proxyResp.Body)),
+		)
 	}
 
-	var usageResp models.CodexUsageResponse
-	if err := json.Unmarshal([]byte(proxyResp.Body), &usageResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal usage response: %v", err)
-	}
+	return &proxyResp, nil
+}
 
-	return &usageResp, nil
+// GetCodexProvider returns the raw ChatGPT usage response for a codex account.
+func (c *Client) GetCodexProvider(ctx context.Context, file models.AuthFile) (*models.CodexUsageResponse, error) {
+	body, err := c.fetchProviderBody(ctx, codexProvider{}, file)
+	if err != nil {
+		return nil, err
+	}
+	return codexProvider{}.parseUsage(body)
 }
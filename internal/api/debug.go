@@ -0,0 +1,124 @@
+package api
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	redacted            = "[REDACTED]"
+	minRedactedTokenLen = 8
+)
+
+// Option configures optional Client behaviour.
+type Option func(*Client)
+
+// WithLogger enables structured debug logging of every management and
+// proxied request.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithManagementTimeout overrides the timeout for management requests. Zero
+// or negative values keep the configured one.
+func WithManagementTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.managementTimeout = d
+		}
+	}
+}
+
+// WithQuotaTimeout overrides the timeout for each proxied request. Zero or
+// negative values keep the configured one.
+func WithQuotaTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.quotaTimeout = d
+		}
+	}
+}
+
+// WithBodyDump includes request and response bodies in debug logs.
+func WithBodyDump(enabled bool) Option {
+	return func(c *Client) {
+		c.dumpBodies = enabled
+	}
+}
+
+// WithUnsafeLogging disables redaction of tokens, account IDs and emails in
+// debug logs.
+func WithUnsafeLogging(enabled bool) Option {
+	return func(c *Client) {
+		c.unsafeLogging = enabled
+	}
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+`)
+	// sensitiveKeyFields also matches fields inside JSON-encoded strings,
+	// such as the upstream body of a proxied response, where the quotes are
+	// escaped as \".
+	sensitiveKeyFields = regexp.MustCompile(`(?i)(\\?"(?:[a-z_\-]*account[_\-]?id|user_id|[a-z_\-]*token|api[_\-]?key|secret)\\?"\s*:\s*)(\\?")(?:[^"\\]|\\[^"])*\\?"`)
+	sensitiveQueryKeys = []string{"key", "token", "secret", "password", "account"}
+)
+
+// redactString removes secrets from free text such as request and response
+// bodies: bearer tokens, the management token, token and account ID fields,
+// and email addresses.
+func (c *Client) redactString(s string) string {
+	if c.unsafeLogging {
+		return s
+	}
+	// Very short tokens would match ordinary words; bearer values are
+	// already covered by bearerPattern.
+	if len(c.cfg.ManagementToken) >= minRedactedTokenLen {
+		s = strings.ReplaceAll(s, c.cfg.ManagementToken, redacted)
+	}
+	s = bearerPattern.ReplaceAllString(s, "${1}"+redacted)
+	s = sensitiveKeyFields.ReplaceAllString(s, "${1}${2}"+redacted+"${2}")
+	s = emailPattern.ReplaceAllString(s, redacted)
+	return s
+}
+
+// redactURL hides credential-like query parameters and emails in a URL.
+func (c *Client) redactURL(raw string) string {
+	if c.unsafeLogging {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return c.redactString(raw)
+	}
+	u.User = nil
+	q := u.Query()
+	for name := range q {
+		lower := strings.ToLower(name)
+		for _, key := range sensitiveQueryKeys {
+			if strings.Contains(lower, key) {
+				q.Set(name, redacted)
+				break
+			}
+		}
+	}
+	u.RawQuery = strings.ReplaceAll(q.Encode(), url.QueryEscape(redacted), redacted)
+	return emailPattern.ReplaceAllString(u.String(), redacted)
+}
+
+func (c *Client) debugEnabled() bool {
+	return c.logger != nil
+}
+
+// logBody adds a redacted body attribute when body dumping is enabled.
+func (c *Client) logBody(attrs []any, key string, body []byte) []any {
+	if !c.dumpBodies || len(body) == 0 {
+		return attrs
+	}
+	return append(attrs, slog.String(key, c.redactString(string(body))))
+}
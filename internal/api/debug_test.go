@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func TestRedactString(t *testing.T) {
+	c, err := NewClient(&config.Config{ServerURL: "http://localhost", ManagementToken: "mgmt-secret"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	input := `{"authIndex":"3","header":{"Authorization":"Bearer $TOKEN$","Chatgpt-Account-Id":"acct-123"},` +
+		`"email":"jane@example.com","access_token":"ya29.abc","note":"mgmt-secret"}`
+	result := c.redactString(input)
+
+	for _, secret := range []string{"$TOKEN$", "acct-123", "jane@example.com", "ya29.abc", "mgmt-secret"} {
+		if strings.Contains(result, secret) {
+			t.Errorf("redactString left %q in %s", secret, result)
+		}
+	}
+	if !strings.Contains(result, `"authIndex":"3"`) {
+		t.Errorf("redactString removed non-sensitive field: %s", result)
+	}
+
+	unsafe, err := NewClient(c.cfg, WithUnsafeLogging(true))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if got := unsafe.redactString(input); got != input {
+		t.Errorf("redactString with unsafe logging = %s; want input unchanged", got)
+	}
+}
+
+func TestRedactStringProxyResponse(t *testing.T) {
+	c, err := NewClient(&config.Config{ServerURL: "http://localhost", ManagementToken: "mgmt-secret"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	upstream := `{"user_id":"user-42","account_id":"acct-123","email":"jane@example.com",` +
+		`"access_token":"ya29.abc","plan_type":"plus"}`
+	body, err := json.Marshal(models.ProxyResponse{StatusCode: 200, Body: upstream})
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	result := c.redactString(string(body))
+
+	for _, secret := range []string{"user-42", "acct-123", "jane@example.com", "ya29.abc"} {
+		if strings.Contains(result, secret) {
+			t.Errorf("redactString left %q in %s", secret, result)
+		}
+	}
+	if !strings.Contains(result, `\"account_id\":\"[REDACTED]\"`) || !strings.Contains(result, `\"plan_type\":\"plus\"`) {
+		t.Errorf("redactString did not keep the escaped structure: %s", result)
+	}
+
+	var resp models.ProxyResponse
+	if err := json.Unmarshal([]byte(result), &resp); err != nil {
+		t.Fatalf("redacted body is no longer valid JSON: %v", err)
+	}
+	if !json.Valid([]byte(resp.Body)) {
+		t.Errorf("redacted upstream body is no longer valid JSON: %s", resp.Body)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	c, err := NewClient(&config.Config{ServerURL: "http://localhost", ManagementToken: "x"})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://chatgpt.com/backend-api/wham/usage", "https://chatgpt.com/backend-api/wham/usage"},
+		{"https://example.com/v1?key=abc&alt=json", "https://example.com/v1?alt=json&key=[REDACTED]"},
+		{"https://user:pw@example.com/u/jane@example.com", "https://example.com/u/[REDACTED]"},
+	}
+
+	for _, test := range tests {
+		result := c.redactURL(test.input)
+		if result != test.expected {
+			t.Errorf("redactURL(%q) = %q; want %q", test.input, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxErrorBody caps how much of an error response body is kept.
+const maxErrorBody = 4096
+
+var (
+	// ErrUnauthorized is matched by ManagementError and UpstreamError values
+	// carrying a 401 or 403 status.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrUnsupportedProvider is returned when no QuotaProvider is registered
+	// for an auth file's provider type.
+	ErrUnsupportedProvider = errors.New("unsupported provider")
+)
+
+// ManagementError reports a non-200 status from the management server itself.
+type ManagementError struct {
+	Endpoint   string
+	StatusCode int
+	Body       string
+}
+
+func (e *ManagementError) Error() string {
+	return fmt.Sprintf("management server returned status %d for %s", e.StatusCode, e.Endpoint)
+}
+
+func (e *ManagementError) Unwrap() error {
+	return unauthorizedFor(e.StatusCode)
+}
+
+func newManagementError(endpoint string, statusCode int, body []byte) *ManagementError {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return &ManagementError{Endpoint: endpoint, StatusCode: statusCode, Body: string(body)}
+}
+
+// UpstreamError reports a non-200 status returned by the provider API
+// behind the management server's api-call proxy.
+type UpstreamError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s API returned status %d", e.Provider, e.StatusCode)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return unauthorizedFor(e.StatusCode)
+}
+
+func unauthorizedFor(statusCode int) error {
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return ErrUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+// QuotaProvider knows how to fetch and interpret quota for one auth file type.
+type QuotaProvider interface {
+	// Name returns the auth file type this provider handles (e.g. "codex").
+	Name() string
+	// BuildRequest returns the upstream request sent through the api-call proxy.
+	BuildRequest(file models.AuthFile) (models.ProxyRequest, error)
+	// ParseResponse converts the upstream response body into model limits.
+	// Provider, Group and FetchedAt are filled in by the caller.
+	ParseResponse(file models.AuthFile, body []byte) ([]models.ModelLimit, error)
+	// DisplayGroup maps a model ID to the group shown in the default view.
+	// An empty string hides the model unless --full is used.
+	DisplayGroup(modelName string) string
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]QuotaProvider)
+)
+
+// RegisterProvider makes a provider available to FetchQuota. Registering the
+// same name twice replaces the earlier provider.
+func RegisterProvider(p QuotaProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name()] = p
+}
+
+// LookupProvider returns the provider registered for name.
+func LookupProvider(name string) (QuotaProvider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// ProviderNames returns the registered provider names in sorted order.
+func ProviderNames() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// extractProjectID returns the auth file's project ID, falling back to the
+// value in parentheses in the account string, e.g. "user@x.com (my-project)".
+func extractProjectID(file models.AuthFile) string {
+	projectID := file.ProjectID
+	if projectID == "" && file.Account != "" {
+		if start := strings.Index(file.Account, "("); start != -1 {
+			if end := strings.Index(file.Account, ")"); end != -1 && end > start {
+				projectID = file.Account[start+1 : end]
+			}
+		}
+	}
+	return projectID
+}
+
+// parseResetTime parses an RFC 3339 reset time, returning the zero time if
+// it is empty or invalid.
+func parseResetTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
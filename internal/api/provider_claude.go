@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func init() {
+	RegisterProvider(claudeProvider{})
+}
+
+// claudeProvider reads subscription utilization from the Anthropic OAuth
+// usage endpoint: a 5-hour window, a weekly window and optional per-model
+// weekly windows.
+type claudeProvider struct{}
+
+func (claudeProvider) Name() string { return "claude" }
+
+func (claudeProvider) BuildRequest(file models.AuthFile) (models.ProxyRequest, error) {
+	return models.ProxyRequest{
+		AuthIndex: file.AuthIndex,
+		Method:    "GET",
+		URL:       claudeUsageURL,
+		Header: map[string]string{
+			"Authorization":  "Bearer $TOKEN$",
+			"Content-Type":   "application/json",
+			"Anthropic-Beta": claudeOAuthBeta,
+		},
+	}, nil
+}
+
+func (claudeProvider) ParseResponse(file models.AuthFile, body []byte) ([]models.ModelLimit, error) {
+	var usageResp models.ClaudeUsageResponse
+	if err := json.Unmarshal(body, &usageResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal usage response: %w", err)
+	}
+
+	const week = 7 * 24 * time.Hour
+	windows := []struct {
+		name   string
+		length time.Duration
+		window *models.ClaudeUsageWindow
+	}{
+		{"claude", 5 * time.Hour, usageResp.FiveHour},
+		{"claude (weekly)", week, usageResp.SevenDay},
+		{"opus (weekly)", week, usageResp.SevenDayOpus},
+		{"sonnet (weekly)", week, usageResp.SevenDaySonnet},
+	}
+
+	var limits []models.ModelLimit
+	for _, w := range windows {
+		if w.window == nil {
+			continue
+		}
+		remaining := 100.0 - w.window.Utilization
+		if remaining < 0 {
+			remaining = 0
+		}
+		limits = append(limits, models.ModelLimit{
+			ModelID:           w.name,
+			RemainingFraction: remaining / 100.0,
+			ResetAt:           parseResetTime(w.window.ResetsAt),
+			Window:            w.length,
+		})
+	}
+
+	return limits, nil
+}
+
+func (claudeProvider) DisplayGroup(modelName string) string {
+	return strings.Title(modelName)
+}
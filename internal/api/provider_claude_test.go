@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func limitsByID(limits []models.ModelLimit) map[string]models.ModelLimit {
+	byID := make(map[string]models.ModelLimit, len(limits))
+	for _, l := range limits {
+		byID[l.ModelID] = l
+	}
+	return byID
+}
+
+func TestClaudeParseResponse(t *testing.T) {
+	body := []byte(`{
+		"five_hour": {"utilization": 25.0, "resets_at": "2025-01-01T15:00:00Z"},
+		"seven_day": {"utilization": 104.0, "resets_at": "2025-01-05T00:00:00Z"},
+		"seven_day_opus": null,
+		"seven_day_sonnet": {"utilization": 10.5, "resets_at": null}
+	}`)
+
+	parsed, err := claudeProvider{}.ParseResponse(models.AuthFile{}, body)
+	if err != nil {
+		t.Fatalf("ParseResponse returned error: %v", err)
+	}
+	limits := limitsByID(parsed)
+
+	tests := []struct {
+		name      string
+		remaining float64
+		resetAt   time.Time
+		window    time.Duration
+	}{
+		{"claude", 0.75, time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC), 5 * time.Hour},
+		{"claude (weekly)", 0, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), 7 * 24 * time.Hour},
+		{"sonnet (weekly)", 0.895, time.Time{}, 7 * 24 * time.Hour},
+	}
+	for _, test := range tests {
+		limit, ok := limits[test.name]
+		if !ok {
+			t.Errorf("missing limit %q", test.name)
+			continue
+		}
+		if limit.RemainingFraction != test.remaining || !limit.ResetAt.Equal(test.resetAt) || limit.Window != test.window {
+			t.Errorf("limits[%q] = %+v; want remaining %v, reset %v, window %v", test.name, limit, test.remaining, test.resetAt, test.window)
+		}
+	}
+	if _, ok := limits["opus (weekly)"]; ok {
+		t.Errorf("unexpected limit for null opus window")
+	}
+}
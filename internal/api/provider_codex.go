@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func init() {
+	RegisterProvider(codexProvider{})
+}
+
+// codexProvider reads the ChatGPT usage endpoint used by the Codex CLI.
+type codexProvider struct{}
+
+func (codexProvider) Name() string { return "codex" }
+
+func (codexProvider) BuildRequest(file models.AuthFile) (models.ProxyRequest, error) {
+	return models.ProxyRequest{
+		AuthIndex: file.AuthIndex,
+		Method:    "GET",
+		URL:       chatgptUsageURL,
+		Header: map[string]string{
+			"Authorization":      "Bearer $TOKEN$",
+			"Content-Type":       "application/json",
+			"User-Agent":         codexUserAgent,
+			"Chatgpt-Account-Id": file.IDToken.ChatgptAccountID,
+		},
+	}, nil
+}
+
+func (codexProvider) parseUsage(body []byte) (*models.CodexUsageResponse, error) {
+	var usageResp models.CodexUsageResponse
+	if err := json.Unmarshal(body, &usageResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal usage response: %w", err)
+	}
+	return &usageResp, nil
+}
+
+func (p codexProvider) ParseResponse(file models.AuthFile, body []byte) ([]models.ModelLimit, error) {
+	resp, err := p.parseUsage(body)
+	if err != nil {
+		return nil, err
+	}
+
+	modelName := resp.PlanType
+	if modelName == "" {
+		modelName = "codex"
+	}
+	primaryBlocked, secondaryBlocked := codexBlockedWindows(resp.RateLimit)
+	now := time.Now()
+
+	primaryLabel := windowLabel(resp.RateLimit.PrimaryWindow.LimitWindowSeconds)
+	primaryName := modelName
+	if primaryLabel != "" {
+		primaryName = fmt.Sprintf("%s (%s)", modelName, primaryLabel)
+	}
+	limits := []models.ModelLimit{
+		codexWindowLimit(primaryName, resp.RateLimit.PrimaryWindow, primaryLabel, primaryBlocked, now),
+	}
+
+	if resp.RateLimit.SecondaryWindow != nil {
+		secondaryLabel := windowLabel(resp.RateLimit.SecondaryWindow.LimitWindowSeconds)
+		if secondaryLabel == "" || secondaryLabel == primaryLabel {
+			secondaryLabel = "secondary"
+		}
+		secondaryName := fmt.Sprintf("%s (%s)", modelName, secondaryLabel)
+		limits = append(limits, codexWindowLimit(secondaryName, *resp.RateLimit.SecondaryWindow, secondaryLabel, secondaryBlocked, now))
+	}
+
+	if resp.Credits != nil {
+		limits = append(limits, codexCreditsLimit(*resp.Credits))
+	}
+
+	return limits, nil
+}
+
+// codexBlockedWindows reports which windows are exhausted. A window is
+// blocked when it is fully used; when the server says the limit is reached
+// (or the request is not allowed) but no window is at 100%, the most used
+// window is taken to be the exhausted one.
+func codexBlockedWindows(rl models.RateLimit) (primary, secondary bool) {
+	primary = rl.PrimaryWindow.UsedPercent >= 100
+	secondary = rl.SecondaryWindow != nil && rl.SecondaryWindow.UsedPercent >= 100
+	if primary || secondary {
+		return primary, secondary
+	}
+
+	reached := rl.LimitReached || (rl.Allowed != nil && !*rl.Allowed)
+	if !reached {
+		return false, false
+	}
+	if rl.SecondaryWindow != nil && rl.SecondaryWindow.UsedPercent > rl.PrimaryWindow.UsedPercent {
+		return false, true
+	}
+	return true, false
+}
+
+// codexWindowLimit converts a rate-limit window into a ModelLimit, preferring
+// the absolute reset time and falling back to the relative one.
+func codexWindowLimit(modelID string, w models.WindowDetails, label string, blocked bool, now time.Time) models.ModelLimit {
+	remaining := 100.0 - w.UsedPercent
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	var resetAt time.Time
+	switch {
+	case w.ResetAt > 0:
+		resetAt = time.Unix(w.ResetAt, 0)
+	case w.ResetAfterSeconds > 0:
+		resetAt = now.Add(time.Duration(w.ResetAfterSeconds) * time.Second)
+	}
+
+	displayName := "Rate limit"
+	if label != "" {
+		displayName = strings.ToUpper(label[:1]) + label[1:] + " limit"
+	}
+
+	return models.ModelLimit{
+		ModelID:           modelID,
+		DisplayName:       displayName,
+		RemainingFraction: remaining / 100.0,
+		ResetAt:           resetAt,
+		Window:            time.Duration(w.LimitWindowSeconds) * time.Second,
+		Blocked:           blocked,
+	}
+}
+
+// codexCreditsLimit reports the account's credit balance. It is only shown in
+// --full mode since DisplayGroup hides it.
+func codexCreditsLimit(c models.CodexCredits) models.ModelLimit {
+	credits := &models.Credits{Unlimited: c.Unlimited, HasCredits: c.HasCredits}
+	if balance, err := c.Balance.Float64(); err == nil && c.Balance != "" {
+		credits.Balance = &balance
+	}
+
+	limit := models.ModelLimit{ModelID: "credits", DisplayName: "Credits", Credits: credits}
+	if c.Unlimited || c.HasCredits || (credits.Balance != nil && *credits.Balance > 0) {
+		limit.RemainingFraction = 1
+	}
+	return limit
+}
+
+// windowLabel names a rate-limit window from its length, e.g. "5h" or
+// "weekly". It returns "" when the length is unknown.
+func windowLabel(seconds int) string {
+	switch {
+	case seconds <= 0:
+		return ""
+	case seconds == 7*24*3600:
+		return "weekly"
+	case seconds == 24*3600:
+		return "daily"
+	case seconds%(24*3600) == 0:
+		return fmt.Sprintf("%dd", seconds/(24*3600))
+	case seconds%3600 == 0:
+		return fmt.Sprintf("%dh", seconds/3600)
+	case seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+func (codexProvider) DisplayGroup(modelName string) string {
+	if modelName == "credits" {
+		return ""
+	}
+	return strings.Title(modelName)
+}
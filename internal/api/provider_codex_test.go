@@ -0,0 +1,142 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func TestWindowLabel(t *testing.T) {
+	tests := []struct {
+		seconds  int
+		expected string
+	}{
+		{0, ""},
+		{18000, "5h"},
+		{604800, "weekly"},
+		{86400, "daily"},
+		{172800, "2d"},
+		{1800, "30m"},
+		{45, "45s"},
+	}
+
+	for _, test := range tests {
+		result := windowLabel(test.seconds)
+		if result != test.expected {
+			t.Errorf("windowLabel(%d) = %q; want %q", test.seconds, result, test.expected)
+		}
+	}
+}
+
+func TestCodexParseResponse(t *testing.T) {
+	body := []byte(`{
+		"plan_type": "plus",
+		"rate_limit": {
+			"allowed": false,
+			"limit_reached": true,
+			"primary_window": {"used_percent": 40, "limit_window_seconds": 18000, "reset_at": 1735750800},
+			"secondary_window": {"used_percent": 100, "limit_window_seconds": 604800, "reset_after_seconds": 3600}
+		},
+		"credits": {"has_credits": true, "unlimited": false, "balance": "12.5"}
+	}`)
+
+	parsed, err := codexProvider{}.ParseResponse(models.AuthFile{}, body)
+	if err != nil {
+		t.Fatalf("ParseResponse returned error: %v", err)
+	}
+	limits := limitsByID(parsed)
+
+	primary, ok := limits["plus (5h)"]
+	if !ok || primary.RemainingFraction != 0.6 || primary.Blocked || primary.Window != 5*time.Hour ||
+		!primary.ResetAt.Equal(time.Unix(1735750800, 0)) {
+		t.Errorf("limits[plus (5h)] = %+v", primary)
+	}
+	secondary, ok := limits["plus (weekly)"]
+	if !ok || secondary.RemainingFraction != 0 || !secondary.Blocked || secondary.ResetAt.IsZero() {
+		t.Errorf("limits[plus (weekly)] = %+v", secondary)
+	}
+	credits, ok := limits["credits"]
+	if !ok || credits.Credits == nil || credits.Credits.Balance == nil || *credits.Credits.Balance != 12.5 || credits.RemainingFraction != 1 {
+		t.Errorf("limits[credits] = %+v", credits)
+	}
+	if group := (codexProvider{}).DisplayGroup("credits"); group != "" {
+		t.Errorf("DisplayGroup(credits) = %q; want hidden", group)
+	}
+}
+
+func TestCodexParseResponseBlocksOnlyExhaustedWindow(t *testing.T) {
+	tests := []struct {
+		name                       string
+		rateLimit                  string
+		wantPrimary, wantSecondary bool
+	}{
+		{
+			name:        "5h window used up",
+			rateLimit:   `{"allowed": false, "limit_reached": true, "primary_window": {"used_percent": 100, "limit_window_seconds": 18000}, "secondary_window": {"used_percent": 35, "limit_window_seconds": 604800}}`,
+			wantPrimary: true,
+		},
+		{
+			name:          "limit reached below 100%",
+			rateLimit:     `{"allowed": true, "limit_reached": true, "primary_window": {"used_percent": 20, "limit_window_seconds": 18000}, "secondary_window": {"used_percent": 99.5, "limit_window_seconds": 604800}}`,
+			wantSecondary: true,
+		},
+		{
+			name:        "not allowed",
+			rateLimit:   `{"allowed": false, "primary_window": {"used_percent": 80, "limit_window_seconds": 18000}, "secondary_window": {"used_percent": 10, "limit_window_seconds": 604800}}`,
+			wantPrimary: true,
+		},
+		{
+			name:      "allowed field missing",
+			rateLimit: `{"primary_window": {"used_percent": 80, "limit_window_seconds": 18000}, "secondary_window": {"used_percent": 10, "limit_window_seconds": 604800}}`,
+		},
+	}
+
+	for _, test := range tests {
+		body := []byte(`{"plan_type": "plus", "rate_limit": ` + test.rateLimit + `}`)
+		parsed, err := codexProvider{}.ParseResponse(models.AuthFile{}, body)
+		if err != nil {
+			t.Fatalf("%s: ParseResponse returned error: %v", test.name, err)
+		}
+		limits := limitsByID(parsed)
+		if got := limits["plus (5h)"].Blocked; got != test.wantPrimary {
+			t.Errorf("%s: 5h Blocked = %v; want %v", test.name, got, test.wantPrimary)
+		}
+		if got := limits["plus (weekly)"].Blocked; got != test.wantSecondary {
+			t.Errorf("%s: weekly Blocked = %v; want %v", test.name, got, test.wantSecondary)
+		}
+	}
+}
+
+func TestCodexParseResponseLenientBalance(t *testing.T) {
+	tests := []struct {
+		balance string
+		want    *float64
+	}{
+		{`""`, nil},
+		{`null`, nil},
+		{`"n/a"`, nil},
+		{`"3.25"`, ptr(3.25)},
+		{`7`, ptr(7)},
+	}
+
+	for _, test := range tests {
+		body := []byte(`{"plan_type": "plus", "rate_limit": {"primary_window": {"used_percent": 0}}, "credits": {"has_credits": false, "balance": ` + test.balance + `}}`)
+		parsed, err := codexProvider{}.ParseResponse(models.AuthFile{}, body)
+		if err != nil {
+			t.Fatalf("balance %s: ParseResponse returned error: %v", test.balance, err)
+		}
+		credits := limitsByID(parsed)["credits"].Credits
+		if credits == nil {
+			t.Fatalf("balance %s: no credits limit", test.balance)
+		}
+		switch {
+		case test.want == nil && credits.Balance != nil:
+			t.Errorf("balance %s: Balance = %v; want unset", test.balance, *credits.Balance)
+		case test.want != nil && (credits.Balance == nil || *credits.Balance != *test.want):
+			t.Errorf("balance %s: Balance = %v; want %v", test.balance, credits.Balance, *test.want)
+		}
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func init() {
+	RegisterProvider(antigravityProvider{})
+	RegisterProvider(geminiCLIProvider{})
+}
+
+// antigravityProvider reads quota from the Cloud Code fetchAvailableModels
+// endpoint, which lists every model with its display name and quota.
+type antigravityProvider struct{}
+
+func (antigravityProvider) Name() string { return "antigravity" }
+
+func (antigravityProvider) BuildRequest(file models.AuthFile) (models.ProxyRequest, error) {
+	return models.ProxyRequest{
+		AuthIndex: file.AuthIndex,
+		Method:    "POST",
+		URL:       googleCloudCodeURL,
+		Header: map[string]string{
+			"Authorization": "Bearer $TOKEN$",
+			"Content-Type":  "application/json",
+			"User-Agent":    antigravityUserAgent,
+		},
+		Data: fmt.Sprintf(`{"project":"%s"}`, extractProjectID(file)),
+	}, nil
+}
+
+func (antigravityProvider) ParseResponse(file models.AuthFile, body []byte) ([]models.ModelLimit, error) {
+	var googleResp models.FetchAvailableModelsResponse
+	if err := json.Unmarshal(body, &googleResp); err != nil {
+		return nil, err
+	}
+
+	limits := make([]models.ModelLimit, 0, len(googleResp.Models))
+	for key, model := range googleResp.Models {
+		limit := models.ModelLimit{
+			ModelID:     key,
+			DisplayName: model.DisplayName,
+			Metadata:    model.Extra,
+		}
+		if model.QuotaInfo != nil {
+			limit.RemainingFraction = model.QuotaInfo.RemainingFraction
+			limit.ResetAt = parseResetTime(model.QuotaInfo.ResetTime)
+		} else {
+			limit.QuotaUnknown = true
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+func (antigravityProvider) DisplayGroup(modelName string) string {
+	lowerModel := strings.ToLower(modelName)
+	if strings.Contains(lowerModel, "claude") {
+		return "Claude/GPT"
+	}
+	if strings.Contains(lowerModel, "gemini") {
+		return "Gemini 3"
+	}
+	return ""
+}
+
+// geminiCLIProvider reads per-model quota buckets from retrieveUserQuota.
+type geminiCLIProvider struct{}
+
+func (geminiCLIProvider) Name() string { return "gemini-cli" }
+
+func (geminiCLIProvider) BuildRequest(file models.AuthFile) (models.ProxyRequest, error) {
+	return models.ProxyRequest{
+		AuthIndex: file.AuthIndex,
+		Method:    "POST",
+		URL:       geminiQuotaURL,
+		Header: map[string]string{
+			"Authorization": "Bearer $TOKEN$",
+			"Content-Type":  "application/json",
+		},
+		Data: fmt.Sprintf(`{"project":"%s"}`, extractProjectID(file)),
+	}, nil
+}
+
+func (geminiCLIProvider) ParseResponse(file models.AuthFile, body []byte) ([]models.ModelLimit, error) {
+	var geminiResp models.GeminiQuotaResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return nil, err
+	}
+
+	var limits []models.ModelLimit
+	for _, bucket := range geminiResp.Buckets {
+		if bucket.ModelID != "" {
+			limits = append(limits, models.ModelLimit{
+				ModelID:           bucket.ModelID,
+				RemainingFraction: bucket.RemainingFraction,
+				ResetAt:           parseResetTime(bucket.ResetTime),
+			})
+		}
+	}
+	return limits, nil
+}
+
+func (geminiCLIProvider) DisplayGroup(modelName string) string {
+	lowerModel := strings.ToLower(modelName)
+	if strings.Contains(lowerModel, "pro") {
+		return "Gemini Pro"
+	}
+	if strings.Contains(lowerModel, "flash") {
+		return "Gemini Flash"
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func TestAntigravityParseResponse(t *testing.T) {
+	body := []byte(`{"models": {
+		"gemini-3-pro-high": {
+			"displayName": "Gemini 3 Pro (High)",
+			"maxTokens": 1048576,
+			"supportsImages": true,
+			"quotaInfo": {"remainingFraction": 0.4, "resetTime": "2030-01-01T00:00:00Z"}
+		},
+		"chat_20706": {"displayName": ""}
+	}}`)
+
+	parsed, err := antigravityProvider{}.ParseResponse(models.AuthFile{}, body)
+	if err != nil {
+		t.Fatalf("ParseResponse returned error: %v", err)
+	}
+	limits := limitsByID(parsed)
+
+	pro := limits["gemini-3-pro-high"]
+	if pro.DisplayName != "Gemini 3 Pro (High)" || pro.RemainingFraction != 0.4 || !pro.ResetAt.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("gemini-3-pro-high = %+v", pro)
+	}
+	if pro.Metadata["maxTokens"] != float64(1048576) || pro.Metadata["supportsImages"] != true {
+		t.Errorf("gemini-3-pro-high metadata = %v", pro.Metadata)
+	}
+	if _, ok := pro.Metadata["quotaInfo"]; ok {
+		t.Errorf("metadata repeats quotaInfo: %v", pro.Metadata)
+	}
+
+	if pro.QuotaUnknown {
+		t.Errorf("gemini-3-pro-high has quota but is marked unknown")
+	}
+	if chat, ok := limits["chat_20706"]; !ok || chat.Metadata != nil || !chat.QuotaUnknown {
+		t.Errorf("chat_20706 = %+v, %v; want entry with unknown quota and no metadata", chat, ok)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 8 * time.Second
+)
+
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// retryPolicyFor resolves the policy for a provider, falling back to the
+// "default" entry and then to the built-in defaults field by field.
+func retryPolicyFor(cfg *config.Config, provider string) retryPolicy {
+	p := retryPolicy{
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+	}
+	for _, key := range []string{"default", provider} {
+		rp, ok := cfg.Retry[key]
+		if !ok {
+			continue
+		}
+		if rp.MaxAttempts > 0 {
+			p.maxAttempts = rp.MaxAttempts
+		}
+		if rp.InitialBackoffMs > 0 {
+			p.initialBackoff = time.Duration(rp.InitialBackoffMs) * time.Millisecond
+		}
+		if rp.MaxBackoffMs > 0 {
+			p.maxBackoff = time.Duration(rp.MaxBackoffMs) * time.Millisecond
+		}
+	}
+	return p
+}
+
+// backoff returns the delay before the given retry (1-based), using
+// exponential growth with jitter in the upper half of the window.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < retry && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// delay returns how long to wait before the given retry. A Retry-After hint
+// replaces the backoff, but if it asks for longer than maxBackoff the retry is
+// not worth waiting for and ok is false.
+func (p retryPolicy) delay(retry int, retryAfter time.Duration) (d time.Duration, ok bool) {
+	if retryAfter > p.maxBackoff {
+		return 0, false
+	}
+	if retryAfter > 0 {
+		return retryAfter, true
+	}
+	return p.backoff(retry), true
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// parseRetryAfter reads a Retry-After value in either delay-seconds or
+// HTTP-date form. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(header map[string][]string, now time.Time) time.Duration {
+	var value string
+	for k, v := range header {
+		if strings.EqualFold(k, "Retry-After") && len(v) > 0 {
+			value = strings.TrimSpace(v[0])
+			break
+		}
+	}
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepWithin waits for d unless ctx is cancelled first. It returns false
+// without waiting if the delay would run past the context deadline.
+func sleepWithin(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header   map[string][]string
+		expected time.Duration
+	}{
+		{nil, 0},
+		{map[string][]string{"Retry-After": {"5"}}, 5 * time.Second},
+		{map[string][]string{"retry-after": {" 2 "}}, 2 * time.Second},
+		{map[string][]string{"Retry-After": {"-1"}}, 0},
+		{map[string][]string{"Retry-After": {"soon"}}, 0},
+		{map[string][]string{"Retry-After": {"Wed, 01 Jan 2025 12:00:30 GMT"}}, 30 * time.Second},
+		{map[string][]string{"Retry-After": {"Wed, 01 Jan 2025 11:59:00 GMT"}}, 0},
+	}
+
+	for _, test := range tests {
+		result := parseRetryAfter(test.header, now)
+		if result != test.expected {
+			t.Errorf("parseRetryAfter(%v) = %v; want %v", test.header, result, test.expected)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := retryPolicy{maxAttempts: 5, initialBackoff: 100 * time.Millisecond, maxBackoff: 300 * time.Millisecond}
+	tests := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 150 * time.Millisecond, 300 * time.Millisecond},
+		{10, 150 * time.Millisecond, 300 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 20; i++ {
+			d := p.backoff(test.retry)
+			if d < test.min || d > test.max {
+				t.Fatalf("backoff(%d) = %v; want within [%v, %v]", test.retry, d, test.min, test.max)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := retryPolicy{maxAttempts: 3, initialBackoff: 100 * time.Millisecond, maxBackoff: 8 * time.Second}
+	tests := []struct {
+		retryAfter time.Duration
+		expected   time.Duration
+		ok         bool
+	}{
+		{5 * time.Second, 5 * time.Second, true},
+		{8 * time.Second, 8 * time.Second, true},
+		{time.Hour, 0, false},
+	}
+
+	for _, test := range tests {
+		d, ok := p.delay(1, test.retryAfter)
+		if d != test.expected || ok != test.ok {
+			t.Errorf("delay(1, %v) = %v, %v; want %v, %v", test.retryAfter, d, ok, test.expected, test.ok)
+		}
+	}
+	if d, ok := p.delay(1, 0); !ok || d > 100*time.Millisecond {
+		t.Errorf("delay(1, 0) = %v, %v; want the backoff", d, ok)
+	}
+}
+
+func TestRetryPolicyFor(t *testing.T) {
+	cfg := &config.Config{Retry: map[string]config.RetryPolicy{
+		"default": {MaxAttempts: 2, MaxBackoffMs: 1000},
+		"codex":   {MaxAttempts: 4},
+	}}
+
+	p := retryPolicyFor(cfg, "codex")
+	if p.maxAttempts != 4 || p.maxBackoff != time.Second || p.initialBackoff != defaultInitialBackoff {
+		t.Errorf("retryPolicyFor(codex) = %+v", p)
+	}
+
+	p = retryPolicyFor(cfg, "gemini-cli")
+	if p.maxAttempts != 2 || p.maxBackoff != time.Second {
+		t.Errorf("retryPolicyFor(gemini-cli) = %+v", p)
+	}
+}
+
+// newRetryServer serves the given upstream responses from the api-call
+// endpoint in order, repeating the last one, and counts the calls.
+func newRetryServer(t *testing.T, responses ...models.ProxyResponse) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		json.NewEncoder(w).Encode(responses[min(n, len(responses))-1])
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newRetryClient(t *testing.T, serverURL string, policy config.RetryPolicy) *Client {
+	t.Helper()
+	c, err := NewClient(&config.Config{
+		ServerURL:       serverURL,
+		ManagementToken: "secret",
+		Retry:           map[string]config.RetryPolicy{"default": policy},
+	})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	return c
+}
+
+func TestAPICallRetriesRateLimit(t *testing.T) {
+	server, calls := newRetryServer(t,
+		models.ProxyResponse{StatusCode: 429, Body: `{"error":"slow down"}`},
+		models.ProxyResponse{StatusCode: 200, Body: `{"plan_type":"plus"}`},
+	)
+	c := newRetryClient(t, server.URL, config.RetryPolicy{MaxAttempts: 3, InitialBackoffMs: 10, MaxBackoffMs: 20})
+
+	usage, err := c.GetCodexProvider(context.Background(), models.AuthFile{AuthIndex: "1"})
+	if err != nil {
+		t.Fatalf("GetCodexProvider returned error: %v", err)
+	}
+	if usage.PlanType != "plus" || calls.Load() != 2 {
+		t.Errorf("GetCodexProvider = %+v after %d calls; want plus after 2", usage, calls.Load())
+	}
+}
+
+func TestAPICallLongRetryAfter(t *testing.T) {
+	server, calls := newRetryServer(t, models.ProxyResponse{
+		StatusCode: 429,
+		Header:     map[string][]string{"Retry-After": {"60"}},
+		Body:       `{"error":"slow down"}`,
+	})
+	c := newRetryClient(t, server.URL, config.RetryPolicy{MaxAttempts: 3, MaxBackoffMs: 1000})
+
+	start := time.Now()
+	_, err := c.GetCodexProvider(context.Background(), models.AuthFile{AuthIndex: "1"})
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != 429 {
+		t.Fatalf("GetCodexProvider error = %v; want a 429 *UpstreamError", err)
+	}
+	if elapsed := time.Since(start); calls.Load() != 1 || elapsed > 500*time.Millisecond {
+		t.Errorf("made %d calls in %v; want one call and no wait", calls.Load(), elapsed)
+	}
+}
+
+func TestAPICallStopsBeforeDeadline(t *testing.T) {
+	server, calls := newRetryServer(t, models.ProxyResponse{StatusCode: 503, Body: `{"error":"unavailable"}`})
+	c := newRetryClient(t, server.URL, config.RetryPolicy{MaxAttempts: 3, InitialBackoffMs: 5000, MaxBackoffMs: 5000})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.GetCodexProvider(ctx, models.AuthFile{AuthIndex: "1"})
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != 503 {
+		t.Fatalf("GetCodexProvider error = %v; want a 503 *UpstreamError", err)
+	}
+	if elapsed := time.Since(start); calls.Load() != 1 || elapsed > 250*time.Millisecond {
+		t.Errorf("made %d calls in %v; want one call and no sleep towards the deadline", calls.Load(), elapsed)
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+)
+
+// DefaultConcurrency is the number of accounts fetched in parallel when
+// neither the config nor the command line sets one.
+const DefaultConcurrency = 8
+
+// newTransport builds the transport shared by every request a Client makes,
+// so quota fetches reuse keep-alive connections to the management server.
+func newTransport(cfg *config.Config, maxConnsPerHost int) (*http.Transport, error) {
+	tlsConfig, err := BuildTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := proxyFunc(cfg.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	dialContext := dialer.DialContext
+	if socketPath, _, ok := config.SplitUnixSocketURL(cfg.ServerURL); ok {
+		// Every request goes to the local socket regardless of the URL host.
+		proxy = nil
+		dialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   maxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
+
+// BuildTLSConfig turns the TLS settings from the config file into a
+// tls.Config. It returns nil when no settings are present.
+func BuildTLSConfig(tc *config.TLSConfig) (*tls.Config, error) {
+	if tc == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         tc.ServerName,
+		InsecureSkipVerify: tc.InsecureSkipVerify,
+	}
+
+	if tc.CABundle != "" {
+		pem, err := os.ReadFile(tc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", tc.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if tc.ClientCert != "" || tc.ClientKey != "" {
+		if tc.ClientCert == "" || tc.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(tc.ClientCert, tc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// proxyFunc returns the proxy selector for the transport: an explicit
+// http, https or socks5 proxy URL, or the standard environment variables.
+func proxyFunc(rawURL string) (func(*http.Request) (*url.URL, error), error) {
+	if rawURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy_url scheme %q (want http, https or socks5)", u.Scheme)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// baseURL returns the prefix for management routes. For unix sockets the
+// host is a placeholder since the transport always dials the socket.
+func baseURL(serverURL string) string {
+	if _, basePath, ok := config.SplitUnixSocketURL(serverURL); ok {
+		return "http://unix" + basePath
+	}
+	return strings.TrimSuffix(serverURL, "/")
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+)
+
+func TestClientOverUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "mgmt.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/base/v0/management/auth-files", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"files":[{"id":"a.json","email":"a@example.com","type":"codex"}]}`))
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{
+		ServerURL:       "unix://" + socketPath + "/base",
+		ManagementToken: "secret",
+		ProxyURL:        "http://127.0.0.1:1",
+	})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	files, err := client.FetchUsage(context.Background())
+	if err != nil {
+		t.Fatalf("FetchUsage returned error: %v", err)
+	}
+	if len(files) != 1 || files[0].ID != "a.json" {
+		t.Errorf("FetchUsage = %+v", files)
+	}
+}
@@ -0,0 +1,157 @@
+// Package cache stores short-lived JSON entries on disk, with per-key file
+// locks so that concurrent qs processes share a single refresh.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockPollInterval is how often a blocked Lock retries.
+const lockPollInterval = 50 * time.Millisecond
+
+// Store is a directory of cache entries.
+type Store struct {
+	dir string
+}
+
+type entry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Open returns a store rooted at dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// DefaultDir returns the per-user cache directory for a named cache.
+func DefaultDir(name string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "quota-sense", name), nil
+}
+
+func (s *Store) path(key, ext string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+ext)
+}
+
+// Get decodes the entry for key into v if it was stored less than maxAge ago,
+// and returns when it was stored.
+func (s *Store) Get(key string, maxAge time.Duration, v any) (storedAt time.Time, ok bool) {
+	data, err := os.ReadFile(s.path(key, ".json"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return time.Time{}, false
+	}
+	if time.Since(e.StoredAt) >= maxAge {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return time.Time{}, false
+	}
+	return e.StoredAt, true
+}
+
+// Put stores v under key. The file is replaced atomically so readers never
+// see a partial entry.
+func (s *Store) Put(key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{StoredAt: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key, ".json"))
+}
+
+// Lock takes an exclusive lock for key, waiting until it is free or ctx is
+// done. The returned function releases it.
+func (s *Store) Lock(ctx context.Context, key string) (func(), error) {
+	return LockFile(ctx, s.path(key, ".lock"))
+}
+
+// LockFile takes an exclusive lock on the file at path, creating it if needed
+// and waiting until it is free or ctx is done. The returned function releases
+// it and, where the platform allows, removes the file.
+func LockFile(ctx context.Context, path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, err
+		}
+		if err := waitLock(ctx, f); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		// The previous holder may have removed the file after we opened it;
+		// a lock on a removed file excludes nobody, so start again.
+		if !isSameFile(f, path) {
+			unlock(f)
+			f.Close()
+			continue
+		}
+		return func() {
+			removeLockFile(path)
+			unlock(f)
+			f.Close()
+		}, nil
+	}
+}
+
+// waitLock polls until the lock on f is taken or ctx is done.
+func waitLock(ctx context.Context, f *os.File) error {
+	for {
+		err := tryLock(f)
+		if !errors.Is(err, errLocked) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func isSameFile(f *os.File, path string) bool {
+	open, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	return err == nil && os.SameFile(open, current)
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	var got []string
+	if _, ok := store.Get("key", time.Minute, &got); ok {
+		t.Fatalf("Get found an entry in an empty store")
+	}
+	before := time.Now()
+	if err := store.Put("key", []string{"a", "b"}); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	storedAt, ok := store.Get("key", time.Minute, &got)
+	if !ok || len(got) != 2 || got[1] != "b" {
+		t.Errorf("Get = %v; want [a b]", got)
+	}
+	if storedAt.Before(before) || storedAt.After(time.Now()) {
+		t.Errorf("Get storedAt = %v; want the time of Put", storedAt)
+	}
+	if _, ok := store.Get("key", 0, &got); ok {
+		t.Errorf("Get returned an entry older than maxAge")
+	}
+	if _, ok := store.Get("other", time.Minute, &got); ok {
+		t.Errorf("Get returned an entry for a different key")
+	}
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+
+	unlock, err := store.Lock(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Lock returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second Lock returned %v; want deadline exceeded", err)
+	}
+
+	unlock()
+	unlock2, err := store.Lock(context.Background(), "key")
+	if err != nil {
+		t.Fatalf("Lock after unlock returned error: %v", err)
+	}
+	unlock2()
+}
+
+func TestLockFileRemovedOnUnlock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("lock files are kept on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	unlock, err := LockFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LockFile returned error: %v", err)
+	}
+
+	// A waiter that opened the file before it was removed must not end up
+	// sharing the lock with a later caller.
+	acquired := make(chan func())
+	go func() {
+		unlock2, err := LockFile(context.Background(), path)
+		if err != nil {
+			t.Errorf("waiting LockFile returned error: %v", err)
+			close(acquired)
+			return
+		}
+		acquired <- unlock2
+	}()
+	time.Sleep(2 * lockPollInterval)
+
+	unlock()
+	unlock2, ok := <-acquired
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if _, err := LockFile(ctx, path); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LockFile while held returned %v; want deadline exceeded", err)
+	}
+
+	unlock2()
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("lock file still exists after unlock: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,64 @@ import (
 type Config struct {
 	ServerURL       string `json:"server_url"`
 	ManagementToken string `json:"management_token"`
+
+	// Per-request timeouts in seconds. Zero means use the client default.
+	ManagementTimeout int `json:"management_timeout_seconds,omitempty"`
+	QuotaTimeout      int `json:"quota_timeout_seconds,omitempty"`
+
+	// Maximum number of accounts fetched in parallel.
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// How long fetched quota is reused from the response cache, in seconds.
+	// Zero means the default; a negative value disables the cache.
+	CacheTTL int `json:"cache_ttl_seconds,omitempty"`
+
+	// Optional TLS settings and outbound proxy for the management server.
+	TLS      *TLSConfig `json:"tls,omitempty"`
+	ProxyURL string     `json:"proxy_url,omitempty"`
+
+	// Retry policies for proxied upstream calls, keyed by provider
+	// (e.g. "codex", "gemini-cli"). The "default" key applies to the rest.
+	Retry map[string]RetryPolicy `json:"retry,omitempty"`
+
+	// Exhaustion policy applied by `qs enforce`. Nothing is enforced
+	// without it.
+	Policy *PolicyConfig `json:"policy,omitempty"`
+}
+
+// TLSConfig customizes how the management server's certificate is verified
+// and, for mTLS, which client certificate is presented.
+type TLSConfig struct {
+	CABundle           string `json:"ca_bundle,omitempty"`
+	ClientCert         string `json:"client_cert,omitempty"`
+	ClientKey          string `json:"client_key,omitempty"`
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+// RetryPolicy controls how upstream 429/5xx responses are retried.
+// Zero values fall back to the client defaults.
+type RetryPolicy struct {
+	MaxAttempts      int `json:"max_attempts,omitempty"`
+	InitialBackoffMs int `json:"initial_backoff_ms,omitempty"`
+	MaxBackoffMs     int `json:"max_backoff_ms,omitempty"`
+}
+
+// PolicyConfig lists the rules `qs enforce` uses to disable exhausted
+// accounts and re-enable them after their quota resets.
+type PolicyConfig struct {
+	Rules []PolicyRule `json:"rules"`
+	// AuditLog overrides the path of the JSON-lines audit log.
+	AuditLog string `json:"audit_log,omitempty"`
+}
+
+// PolicyRule disables an account when a matching limit's remaining quota is
+// at or below FloorPercent, or the provider reports it as blocked. Empty
+// Provider matches every provider; empty Group matches every grouped limit.
+type PolicyRule struct {
+	Provider     string  `json:"provider,omitempty"`
+	Group        string  `json:"group,omitempty"`
+	FloorPercent float64 `json:"floor_percent"`
 }
 
 func GetConfigPath() string {
@@ -34,10 +93,63 @@ func LoadConfig() (*Config, error) {
 	if cfg.ServerURL == "" || cfg.ManagementToken == "" {
 		return nil, fmt.Errorf("invalid config")
 	}
+	if err := ValidateServerURL(cfg.ServerURL); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
+// ValidateServerURL checks that the server URL is an http(s) URL with a host
+// or a unix:// socket URL.
+func ValidateServerURL(raw string) error {
+	if _, _, ok := SplitUnixSocketURL(raw); ok {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid server URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("invalid server URL %q: missing host", raw)
+		}
+		return nil
+	case "unix":
+		return fmt.Errorf("invalid server URL %q: missing socket path", raw)
+	default:
+		return fmt.Errorf("invalid server URL %q: scheme must be http, https or unix", raw)
+	}
+}
+
+// SplitUnixSocketURL splits a unix:// server URL into the socket path and an
+// optional base path for the HTTP routes. The socket path ends at the first
+// path element with a ".sock" suffix; without one the whole path is the socket.
+//
+//	unix:///run/cliproxy/mgmt.sock       -> /run/cliproxy/mgmt.sock, ""
+//	unix:///run/cliproxy/mgmt.sock/proxy -> /run/cliproxy/mgmt.sock, "/proxy"
+func SplitUnixSocketURL(raw string) (socketPath, basePath string, ok bool) {
+	rest, found := strings.CutPrefix(raw, "unix://")
+	if !found || rest == "" || !strings.HasPrefix(rest, "/") {
+		return "", "", false
+	}
+
+	elems := strings.Split(rest, "/")
+	for i, elem := range elems {
+		if strings.HasSuffix(elem, ".sock") {
+			socketPath = strings.Join(elems[:i+1], "/")
+			basePath = strings.TrimSuffix(strings.Join(elems[i+1:], "/"), "/")
+			if basePath != "" {
+				basePath = "/" + basePath
+			}
+			return socketPath, basePath, true
+		}
+	}
+	socketPath = strings.TrimSuffix(rest, "/")
+	return socketPath, "", socketPath != ""
+}
+
 func SaveConfig(cfg *Config) error {
 	path := GetConfigPath()
 	data, err := json.MarshalIndent(cfg, "", "  ")
@@ -48,13 +160,18 @@ func SaveConfig(cfg *Config) error {
 	return os.WriteFile(path, data, 0600)
 }
 
+// PromptConfig asks for the server URL and management token. Other settings
+// already present in the config file are kept.
 func PromptConfig() (*Config, error) {
 	reader := bufio.NewReader(os.Stdin)
 	var cfg Config
+	if data, err := os.ReadFile(GetConfigPath()); err == nil {
+		_ = json.Unmarshal(data, &cfg)
+	}
 
 	fmt.Println("=== QuotaSense Configuration ===")
 
-	fmt.Print("Enter Remote Server URL (e.g., http://localhost:8080): ")
+	fmt.Print("Enter Remote Server URL (e.g., http://localhost:8080 or unix:///run/cliproxy/mgmt.sock): ")
 	url, _ := reader.ReadString('\n')
 	cfg.ServerURL = strings.TrimSpace(url)
 
@@ -65,6 +182,10 @@ func PromptConfig() (*Config, error) {
 	if cfg.ServerURL == "" || cfg.ManagementToken == "" {
 		return nil, fmt.Errorf("server URL and Management Token are required")
 	}
+	cfg.ServerURL = strings.TrimSuffix(cfg.ServerURL, "/")
+	if err := ValidateServerURL(cfg.ServerURL); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestSplitUnixSocketURL(t *testing.T) {
+	tests := []struct {
+		input      string
+		socketPath string
+		basePath   string
+		ok         bool
+	}{
+		{"unix:///run/cliproxy/mgmt.sock", "/run/cliproxy/mgmt.sock", "", true},
+		{"unix:///run/cliproxy/mgmt.sock/", "/run/cliproxy/mgmt.sock", "", true},
+		{"unix:///run/cliproxy/mgmt.sock/proxy/v1", "/run/cliproxy/mgmt.sock", "/proxy/v1", true},
+		{"unix:///tmp/mgmt", "/tmp/mgmt", "", true},
+		{"unix://", "", "", false},
+		{"unix:///", "", "", false},
+		{"unix://relative.sock", "", "", false},
+		{"http://localhost:8080", "", "", false},
+	}
+
+	for _, test := range tests {
+		socketPath, basePath, ok := SplitUnixSocketURL(test.input)
+		if socketPath != test.socketPath || basePath != test.basePath || ok != test.ok {
+			t.Errorf("SplitUnixSocketURL(%q) = %q, %q, %v; want %q, %q, %v",
+				test.input, socketPath, basePath, ok, test.socketPath, test.basePath, test.ok)
+		}
+	}
+}
+
+func TestValidateServerURL(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"http://localhost:8080", true},
+		{"https://mgmt.example.com/base", true},
+		{"unix:///run/cliproxy/mgmt.sock", true},
+		{"unix://", false},
+		{"localhost:8080", false},
+		{"ftp://example.com", false},
+		{"http://", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateServerURL(test.input)
+		if (err == nil) != test.valid {
+			t.Errorf("ValidateServerURL(%q) error = %v; want valid %v", test.input, err, test.valid)
+		}
+	}
+}
@@ -1,7 +1,16 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type AuthFile struct {
 	ID        string  `json:"id"`
+	Name      string  `json:"name"`
 	Email     string  `json:"email"`
 	Provider  string  `json:"type"`
 	Disabled  bool    `json:"disabled"`
@@ -9,6 +18,19 @@ type AuthFile struct {
 	ProjectID string  `json:"project_id"`
 	Account   string  `json:"account"`
 	IDToken   IDToken `json:"id_token"`
+
+	// Raw is the auth file entry exactly as the server returned it,
+	// including fields not modelled above.
+	Raw json.RawMessage `json:"-"`
+}
+
+func (f *AuthFile) UnmarshalJSON(data []byte) error {
+	type plain AuthFile
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	f.Raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 type IDToken struct {
@@ -19,6 +41,53 @@ type AuthFilesResponse struct {
 	Files []AuthFile `json:"files"`
 }
 
+// UsageStatisticsResponse is the management server's request usage
+// statistics. Per-request details are grouped by client API key and model.
+type UsageStatisticsResponse struct {
+	Usage struct {
+		TotalRequests int64                   `json:"total_requests"`
+		SuccessCount  int64                   `json:"success_count"`
+		FailureCount  int64                   `json:"failure_count"`
+		TotalTokens   int64                   `json:"total_tokens"`
+		APIs          map[string]APIUsageStat `json:"apis"`
+	} `json:"usage"`
+}
+
+type APIUsageStat struct {
+	TotalRequests int64                     `json:"total_requests"`
+	TotalTokens   int64                     `json:"total_tokens"`
+	Models        map[string]ModelUsageStat `json:"models"`
+}
+
+type ModelUsageStat struct {
+	TotalRequests int64         `json:"total_requests"`
+	TotalTokens   int64         `json:"total_tokens"`
+	Details       []UsageDetail `json:"details"`
+}
+
+// UsageDetail is a single proxied request.
+type UsageDetail struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Source    string     `json:"source"`
+	AuthIndex string     `json:"auth_index"`
+	Tokens    TokenStats `json:"tokens"`
+	Failed    bool       `json:"failed"`
+}
+
+type TokenStats struct {
+	InputTokens     int64 `json:"input_tokens"`
+	OutputTokens    int64 `json:"output_tokens"`
+	ReasoningTokens int64 `json:"reasoning_tokens"`
+	CachedTokens    int64 `json:"cached_tokens"`
+	TotalTokens     int64 `json:"total_tokens"`
+}
+
+// AuthFileStatusRequest enables or disables an auth file by name.
+type AuthFileStatusRequest struct {
+	Name     string `json:"name"`
+	Disabled bool   `json:"disabled"`
+}
+
 type ProxyRequest struct {
 	AuthIndex string            `json:"authIndex"`
 	Method    string            `json:"method"`
@@ -28,15 +97,51 @@ type ProxyRequest struct {
 }
 
 type ProxyResponse struct {
-	StatusCode int    `json:"status_code"`
-	Body       string `json:"body"`
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header"`
+	Body       string              `json:"body"`
 }
 
+// ModelLimit is the canonical quota reading for one model or rate-limit
+// window. Values are kept in their natural types; formatting is left to
+// renderers.
 type ModelLimit struct {
-	Remaining         string  `json:"remaining"`
-	RemainingFraction float64 `json:"remainingFraction"`
-	ResetTime         string  `json:"resetTime"`
-	DisplayName       string  `json:"displayName"`
+	// ModelID is the model or window identifier as reported by the provider.
+	ModelID string `json:"model_id"`
+	// Provider is the auth file type the limit was fetched for.
+	Provider string `json:"provider"`
+	// DisplayName is the provider's human-readable name, if any.
+	DisplayName string `json:"display_name,omitempty"`
+	// Group is the name the model is grouped under in summary views. It is
+	// empty for models that are only shown when all models are requested.
+	Group string `json:"group,omitempty"`
+	// RemainingFraction is the remaining quota between 0 and 1.
+	RemainingFraction float64 `json:"remaining_fraction"`
+	// QuotaUnknown is set when the provider lists the model without any
+	// quota information; RemainingFraction is then meaningless.
+	QuotaUnknown bool `json:"quota_unknown,omitempty"`
+	// ResetAt is when the quota resets; zero if unknown.
+	ResetAt time.Time `json:"reset_at,omitzero"`
+	// Window is the length of the rate-limit window; zero if unknown.
+	Window time.Duration `json:"window,omitempty"`
+	// Blocked is set when the provider reports the limit as reached,
+	// regardless of the remaining fraction.
+	Blocked bool `json:"blocked,omitempty"`
+	// Credits is set for entries that report a credit balance rather than
+	// a percentage.
+	Credits *Credits `json:"credits,omitempty"`
+	// FetchedAt is when the limit was read from the provider.
+	FetchedAt time.Time `json:"fetched_at"`
+	// Metadata holds any other per-model details the provider reports,
+	// such as token limits or capabilities.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Credits is a prepaid credit balance.
+type Credits struct {
+	Unlimited  bool     `json:"unlimited,omitempty"`
+	HasCredits bool     `json:"has_credits,omitempty"`
+	Balance    *float64 `json:"balance,omitempty"`
 }
 
 // Google response structures
@@ -48,6 +153,25 @@ type GoogleQuotaInfo struct {
 type GoogleModel struct {
 	DisplayName string           `json:"displayName"`
 	QuotaInfo   *GoogleQuotaInfo `json:"quotaInfo"`
+
+	// Extra holds the remaining fields of the model entry.
+	Extra map[string]any `json:"-"`
+}
+
+func (m *GoogleModel) UnmarshalJSON(data []byte) error {
+	type plain GoogleModel
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &m.Extra); err != nil {
+		return err
+	}
+	delete(m.Extra, "displayName")
+	delete(m.Extra, "quotaInfo")
+	if len(m.Extra) == 0 {
+		m.Extra = nil
+	}
+	return nil
 }
 
 type FetchAvailableModelsResponse struct {
@@ -67,17 +191,54 @@ type GeminiQuotaResponse struct {
 
 // Codex response structures
 type CodexUsageResponse struct {
-	UserID    string    `json:"user_id"`
-	AccountID string    `json:"account_id"`
-	Email     string    `json:"email"`
-	PlanType  string    `json:"plan_type"`
-	RateLimit RateLimit `json:"rate_limit"`
-	Credits   any       `json:"credits"`
-	Promo     any       `json:"promo"`
+	UserID    string        `json:"user_id"`
+	AccountID string        `json:"account_id"`
+	Email     string        `json:"email"`
+	PlanType  string        `json:"plan_type"`
+	RateLimit RateLimit     `json:"rate_limit"`
+	Credits   *CodexCredits `json:"credits"`
+	Promo     any           `json:"promo"`
+}
+
+type CodexCredits struct {
+	HasCredits bool        `json:"has_credits"`
+	Unlimited  bool        `json:"unlimited"`
+	Balance    json.Number `json:"balance"`
+}
+
+// UnmarshalJSON accepts the balance as a number or a numeric string. Empty or
+// non-numeric balances are left unset instead of failing the whole response.
+func (c *CodexCredits) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		HasCredits bool            `json:"has_credits"`
+		Unlimited  bool            `json:"unlimited"`
+		Balance    json.RawMessage `json:"balance"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = CodexCredits{HasCredits: raw.HasCredits, Unlimited: raw.Unlimited}
+
+	var balance any
+	dec := json.NewDecoder(bytes.NewReader(raw.Balance))
+	dec.UseNumber()
+	if len(raw.Balance) == 0 || dec.Decode(&balance) != nil {
+		return nil
+	}
+	switch b := balance.(type) {
+	case json.Number:
+		c.Balance = b
+	case string:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(b), 64); err == nil {
+			c.Balance = json.Number(strings.TrimSpace(b))
+		}
+	}
+	return nil
 }
 
 type RateLimit struct {
-	Allowed         bool           `json:"allowed"`
+	// Allowed is nil when the response omits it.
+	Allowed         *bool          `json:"allowed"`
 	LimitReached    bool           `json:"limit_reached"`
 	PrimaryWindow   WindowDetails  `json:"primary_window"`
 	SecondaryWindow *WindowDetails `json:"secondary_window"`
@@ -89,3 +250,16 @@ type WindowDetails struct {
 	ResetAfterSeconds  int     `json:"reset_after_seconds"`
 	ResetAt            int64   `json:"reset_at"`
 }
+
+// Claude response structures
+type ClaudeUsageWindow struct {
+	Utilization float64 `json:"utilization"`
+	ResetsAt    string  `json:"resets_at"`
+}
+
+type ClaudeUsageResponse struct {
+	FiveHour       *ClaudeUsageWindow `json:"five_hour"`
+	SevenDay       *ClaudeUsageWindow `json:"seven_day"`
+	SevenDayOpus   *ClaudeUsageWindow `json:"seven_day_opus"`
+	SevenDaySonnet *ClaudeUsageWindow `json:"seven_day_sonnet"`
+}
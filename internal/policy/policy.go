@@ -0,0 +1,212 @@
+// Package policy decides which accounts to disable when their quota is
+// exhausted and when to enable them again after the quota resets.
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+)
+
+// Kind is what an Action does to an account.
+type Kind string
+
+const (
+	// Disable turns off an account that breached a rule.
+	Disable Kind = "disable"
+	// Enable turns an account back on after a refetch shows it recovered.
+	Enable Kind = "enable"
+	// Forget drops the record of an account that was enabled outside the
+	// policy engine.
+	Forget Kind = "forget"
+)
+
+// Account is an account the engine can disable or enable.
+type Account struct {
+	ID       string
+	Email    string
+	Provider string
+	Disabled bool
+}
+
+// Limit is the part of an account's quota that rules are checked against.
+type Limit struct {
+	ModelID           string
+	Provider          string
+	Group             string
+	RemainingFraction float64
+	// QuotaUnknown is set when the provider reports no quota for the model.
+	QuotaUnknown bool
+	ResetAt      time.Time
+	Blocked      bool
+	// Credits is set for credit balances, which have no fraction to compare
+	// with a floor.
+	Credits bool
+}
+
+// Result is the quota fetched for one account.
+type Result struct {
+	Account Account
+	Limits  []Limit
+	// Err is set when the quota could not be fetched.
+	Err error
+}
+
+// Action is a change the engine wants to make.
+type Action struct {
+	Kind    Kind
+	Account Account
+	Reason  string
+	// Entry is the state recorded for a Disable, or the one being cleared
+	// for Enable and Forget.
+	Entry Entry
+}
+
+// breach is a limit that violates a rule.
+type breach struct {
+	rule  config.PolicyRule
+	limit Limit
+}
+
+// exhausted reports whether the limit is used up rather than just below the
+// rule's floor.
+func (b breach) exhausted() bool {
+	return b.limit.Blocked || b.limit.RemainingFraction <= 0
+}
+
+func (b breach) String() string {
+	name := b.limit.Group
+	if name == "" {
+		name = b.limit.ModelID
+	}
+	if b.limit.Blocked {
+		return fmt.Sprintf("%s %s is blocked", b.limit.Provider, name)
+	}
+	return fmt.Sprintf("%s %s at %.0f%% (floor %.0f%%)", b.limit.Provider, name, b.limit.RemainingFraction*100, b.rule.FloorPercent)
+}
+
+// Evaluate compares fetched quota against the rules and the accounts the engine
+// has already disabled. Accounts disabled by someone else are never touched,
+// and accounts whose quota could not be fetched are left as they are, except
+// that an account the engine disabled is re-enabled once its reset time has
+// passed, since the server may refuse to fetch quota for disabled accounts;
+// if it is still exhausted, the next run disables it again. The recorded
+// reset time of an account that is still exhausted after its reset is moved
+// to the next one.
+func Evaluate(rules []config.PolicyRule, results []Result, state *State, now time.Time) []Action {
+	var actions []Action
+	for _, res := range results {
+		a := res.Account
+		entry, managed := state.Disabled[a.ID]
+
+		if managed && !a.Disabled {
+			actions = append(actions, Action{Kind: Forget, Account: a, Entry: entry,
+				Reason: "enabled outside the policy engine"})
+			continue
+		}
+		if managed && res.Err != nil {
+			if !now.Before(entry.ResetAt) {
+				actions = append(actions, Action{Kind: Enable, Account: a, Entry: entry,
+					Reason: fmt.Sprintf("reset passed but quota could not be fetched (%v); re-enabled to re-check", res.Err)})
+			}
+			continue
+		}
+		if res.Err != nil || (a.Disabled && !managed) {
+			continue
+		}
+
+		breaches := findBreaches(rules, res.Limits)
+		switch {
+		case managed && now.Before(entry.ResetAt):
+			// Still waiting for the reset recorded when it was disabled.
+		case managed && len(breaches) == 0:
+			actions = append(actions, Action{Kind: Enable, Account: a, Entry: entry,
+				Reason: "quota recovered after reset"})
+		case managed:
+			// The reset passed but the quota is still exhausted; wait for
+			// the next one.
+			entry.ResetAt = latestReset(breaches)
+			entry.Reason = describe(breaches)
+			state.Disabled[a.ID] = entry
+		case len(breaches) > 0:
+			entry := Entry{
+				Email:      a.Email,
+				Provider:   a.Provider,
+				Reason:     describe(breaches),
+				ResetAt:    latestReset(breaches),
+				DisabledAt: now,
+			}
+			actions = append(actions, Action{Kind: Disable, Account: a, Entry: entry, Reason: entry.Reason})
+		}
+	}
+	return actions
+}
+
+// findBreaches returns the limits that violate any rule.
+func findBreaches(rules []config.PolicyRule, limits []Limit) []breach {
+	var breaches []breach
+	for _, limit := range limits {
+		if limit.QuotaUnknown {
+			continue
+		}
+		for _, rule := range rules {
+			if !ruleMatches(rule, limit) {
+				continue
+			}
+			if limit.Blocked || (!limit.Credits && limit.RemainingFraction*100 <= rule.FloorPercent) {
+				breaches = append(breaches, breach{rule, limit})
+				break
+			}
+		}
+	}
+	return breaches
+}
+
+func ruleMatches(rule config.PolicyRule, limit Limit) bool {
+	if rule.Provider != "" && rule.Provider != limit.Provider {
+		return false
+	}
+	if rule.Group == "" {
+		return limit.Group != ""
+	}
+	return strings.EqualFold(rule.Group, limit.Group) || strings.EqualFold(rule.Group, limit.ModelID)
+}
+
+// latestReset is when every exhausted limit will have reset, or zero if any
+// reset time is unknown, in which case recovery is checked on every run.
+// Limits that are merely below their floor only count when nothing is
+// exhausted, so an account that used up its 5h window is not held until its
+// weekly window resets; if a limit is still below its floor after the reset,
+// Evaluate moves the reset on.
+func latestReset(breaches []breach) time.Time {
+	exhausted := breaches[:0:0]
+	for _, b := range breaches {
+		if b.exhausted() {
+			exhausted = append(exhausted, b)
+		}
+	}
+	if len(exhausted) > 0 {
+		breaches = exhausted
+	}
+
+	var latest time.Time
+	for _, b := range breaches {
+		if b.limit.ResetAt.IsZero() {
+			return time.Time{}
+		}
+		if b.limit.ResetAt.After(latest) {
+			latest = b.limit.ResetAt
+		}
+	}
+	return latest
+}
+
+func describe(breaches []breach) string {
+	parts := make([]string, len(breaches))
+	for i, b := range breaches {
+		parts[i] = b.String()
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/config"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(2 * time.Hour)
+	rules := []config.PolicyRule{{Provider: "codex", Group: "5h", FloorPercent: 5}}
+
+	limit := func(fraction float64, resetAt time.Time) []Limit {
+		return []Limit{
+			{ModelID: "5h", Provider: "codex", Group: "5h", RemainingFraction: fraction, ResetAt: resetAt},
+			{ModelID: "weekly", Provider: "codex", Group: "weekly", RemainingFraction: 0},
+		}
+	}
+	account := func(id string, disabled bool) Account {
+		return Account{ID: id, Email: id + "@example.com", Provider: "codex", Disabled: disabled}
+	}
+
+	state := &State{Disabled: map[string]Entry{
+		"waiting":   {ResetAt: reset},
+		"recovered": {ResetAt: now.Add(-time.Minute)},
+		"still-out": {ResetAt: now.Add(-time.Minute)},
+		"manual-on": {ResetAt: reset},
+	}}
+	results := []Result{
+		{Account: account("exhausted", false), Limits: limit(0.03, reset)},
+		{Account: account("healthy", false), Limits: limit(0.5, reset)},
+		{Account: account("manual-off", true), Limits: limit(1, reset)},
+		{Account: account("waiting", true), Limits: limit(1, reset)},
+		{Account: account("recovered", true), Limits: limit(1, reset)},
+		{Account: account("still-out", true), Limits: limit(0, reset.Add(time.Hour))},
+		{Account: account("manual-on", false), Limits: limit(1, reset)},
+	}
+
+	actions := Evaluate(rules, results, state, now)
+	got := make(map[string]Kind)
+	for _, a := range actions {
+		got[a.Account.ID] = a.Kind
+	}
+	expected := map[string]Kind{"exhausted": Disable, "recovered": Enable, "manual-on": Forget}
+	if len(got) != len(expected) {
+		t.Errorf("Evaluate actions = %v; want %v", got, expected)
+	}
+	for id, kind := range expected {
+		if got[id] != kind {
+			t.Errorf("action for %s = %q; want %q", id, got[id], kind)
+		}
+	}
+
+	if e := actions[0].Entry; !e.ResetAt.Equal(reset) || e.DisabledAt != now {
+		t.Errorf("disable entry = %+v; want reset %v", e, reset)
+	}
+	if e := state.Disabled["still-out"]; !e.ResetAt.Equal(reset.Add(time.Hour)) {
+		t.Errorf("still-out reset = %v; want it moved to the next reset", e.ResetAt)
+	}
+}
+
+func TestEvaluateManagedFetchError(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	rules := []config.PolicyRule{{Provider: "codex", FloorPercent: 5}}
+	fetchErr := errors.New("auth file is disabled")
+	account := func(id string) Account {
+		return Account{ID: id, Email: id + "@example.com", Provider: "codex", Disabled: true}
+	}
+
+	state := &State{Disabled: map[string]Entry{
+		"waiting":  {ResetAt: now.Add(time.Hour)},
+		"reset":    {ResetAt: now.Add(-time.Minute)},
+		"no-reset": {},
+	}}
+	results := []Result{
+		{Account: account("waiting"), Err: fetchErr},
+		{Account: account("reset"), Err: fetchErr},
+		{Account: account("no-reset"), Err: fetchErr},
+		{Account: account("manual-off"), Err: fetchErr},
+	}
+
+	actions := Evaluate(rules, results, state, now)
+	if len(actions) != 2 || actions[0].Account.ID != "reset" || actions[1].Account.ID != "no-reset" {
+		t.Fatalf("Evaluate actions = %+v; want reset and no-reset re-enabled", actions)
+	}
+	for _, a := range actions {
+		if a.Kind != Enable || !strings.Contains(a.Reason, fetchErr.Error()) {
+			t.Errorf("action for %s = %s %q; want an enable naming the fetch error", a.Account.ID, a.Kind, a.Reason)
+		}
+	}
+}
+
+func TestFindBreachesBlockedAndCredits(t *testing.T) {
+	rules := []config.PolicyRule{{FloorPercent: 0}}
+	limits := []Limit{
+		{ModelID: "weekly", Group: "weekly", RemainingFraction: 0.4, Blocked: true},
+		{ModelID: "credits", Credits: true},
+		{ModelID: "hidden", RemainingFraction: 0},
+		{ModelID: "chat_20706", Group: "Gemini 3", QuotaUnknown: true},
+	}
+	breaches := findBreaches(rules, limits)
+	if len(breaches) != 1 || breaches[0].limit.ModelID != "weekly" {
+		t.Errorf("findBreaches = %+v; want only the blocked weekly limit", breaches)
+	}
+}
+
+func TestEvaluateCodexOnlyShortWindowExhausted(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	shortReset := now.Add(3 * time.Hour)
+	weeklyReset := now.Add(6 * 24 * time.Hour)
+	rules := []config.PolicyRule{{Provider: "codex", FloorPercent: 5}}
+
+	for _, weekly := range []float64{0.6, 0.03} {
+		results := []Result{{
+			Account: Account{ID: "a", Email: "a@example.com", Provider: "codex"},
+			Limits: []Limit{
+				{ModelID: "plus (5h)", Provider: "codex", Group: "Plus (5h)", RemainingFraction: 0, Blocked: true, ResetAt: shortReset},
+				{ModelID: "plus (weekly)", Provider: "codex", Group: "Plus (weekly)", RemainingFraction: weekly, ResetAt: weeklyReset},
+			},
+		}}
+
+		actions := Evaluate(rules, results, &State{Disabled: map[string]Entry{}}, now)
+		if len(actions) != 1 || actions[0].Kind != Disable {
+			t.Fatalf("weekly %.2f: Evaluate actions = %+v; want one disable", weekly, actions)
+		}
+		if got := actions[0].Entry.ResetAt; !got.Equal(shortReset) {
+			t.Errorf("weekly %.2f: disabled until %v; want the 5h reset %v", weekly, got, shortReset)
+		}
+	}
+}
+
+func TestLockState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	unlock, err := LockState(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LockState returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := LockState(ctx, path); err == nil {
+		t.Fatal("second LockState succeeded while the state was locked")
+	}
+
+	unlock()
+	unlock2, err := LockState(context.Background(), path)
+	if err != nil {
+		t.Fatalf("LockState after unlock returned error: %v", err)
+	}
+	unlock2()
+}
@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/cache"
+)
+
+// Entry records why the engine disabled an account.
+type Entry struct {
+	Email      string    `json:"email"`
+	Provider   string    `json:"provider"`
+	Reason     string    `json:"reason"`
+	ResetAt    time.Time `json:"reset_at,omitzero"`
+	DisabledAt time.Time `json:"disabled_at"`
+}
+
+// State is the set of accounts the engine has disabled, keyed by account ID.
+type State struct {
+	Disabled map[string]Entry `json:"disabled"`
+}
+
+// Apply records the effect of a successfully applied action.
+func (s *State) Apply(a Action) {
+	switch a.Kind {
+	case Disable:
+		s.Disabled[a.Account.ID] = a.Entry
+	case Enable, Forget:
+		delete(s.Disabled, a.Account.ID)
+	}
+}
+
+// LockState takes an exclusive lock on the state file at path so that
+// concurrent enforce runs do not overwrite each other's changes. It should be
+// held from LoadState until Save. The returned function releases it.
+func LockState(ctx context.Context, path string) (func(), error) {
+	return cache.LockFile(ctx, path+".lock")
+}
+
+// LoadState reads the state file, returning an empty state if it does not
+// exist yet.
+func LoadState(path string) (*State, error) {
+	state := &State{Disabled: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Disabled == nil {
+		state.Disabled = make(map[string]Entry)
+	}
+	return state, nil
+}
+
+// Save writes the state file.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// AuditRecord is one line of the audit log.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Action    Kind      `json:"action"`
+	AccountID string    `json:"account_id"`
+	Email     string    `json:"email"`
+	Provider  string    `json:"provider"`
+	Reason    string    `json:"reason"`
+	ResetAt   time.Time `json:"reset_at,omitzero"`
+	DryRun    bool      `json:"dry_run,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewAuditRecord describes an action for the audit log.
+func NewAuditRecord(a Action, now time.Time, dryRun bool, err error) AuditRecord {
+	rec := AuditRecord{
+		Time:      now,
+		Action:    a.Kind,
+		AccountID: a.Account.ID,
+		Email:     a.Account.Email,
+		Provider:  a.Account.Provider,
+		Reason:    a.Reason,
+		ResetAt:   a.Entry.ResetAt,
+		DryRun:    dryRun,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	return rec
+}
+
+// AppendAudit appends records to the JSON-lines audit log at path.
+func AppendAudit(path string, records ...AuditRecord) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
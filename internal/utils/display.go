@@ -2,44 +2,13 @@ package utils
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 )
 
-// GetDisplayModelName returns a user-friendly name for a given model ID and provider.
-// If fullMode is true, it returns the original modelName.
-func GetDisplayModelName(modelName, provider string, fullMode bool) string {
-	if fullMode {
-		return modelName
-	}
-
-	if provider == "antigravity" {
-		lowerModel := strings.ToLower(modelName)
-		if strings.Contains(lowerModel, "claude") {
-			return "Claude/GPT"
-		}
-		if strings.Contains(lowerModel, "gemini") {
-			return "Gemini 3"
-		}
-		return "" // Signal to skip this model
-	} else if provider == "gemini-cli" {
-		lowerModel := strings.ToLower(modelName)
-		if strings.Contains(lowerModel, "pro") {
-			return "Gemini Pro"
-		}
-		if strings.Contains(lowerModel, "flash") {
-			return "Gemini Flash"
-		}
-		return "" // Signal to skip this model
-	} else if provider == "codex" {
-		return strings.Title(modelName)
-	}
-
-	return modelName
-}
-
 // GetQuotaColor returns a color based on the remaining quota percentage.
 func GetQuotaColor(remainingVal int) *color.Color {
 	if remainingVal > 50 {
@@ -68,13 +37,10 @@ func FormatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dm", m)
 }
 
-// GetResetString returns a formatted string for the reset time.
-func GetResetString(resetTimeStr string) string {
-	if resetTimeStr == "" {
-		return "-"
-	}
-	resetTime, err := time.Parse(time.RFC3339, resetTimeStr)
-	if err != nil {
+// FormatResetIn returns how long until resetTime, "Now" if it has passed, or
+// "-" if it is unknown.
+func FormatResetIn(resetTime time.Time) string {
+	if resetTime.IsZero() {
 		return "-"
 	}
 	duration := time.Until(resetTime)
@@ -83,3 +49,23 @@ func GetResetString(resetTimeStr string) string {
 	}
 	return "Now"
 }
+
+// FormatCount formats n with thousands separators (e.g., "1,234,567").
+func FormatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if neg {
+		return "-" + b.String()
+	}
+	return b.String()
+}
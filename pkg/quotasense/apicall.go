@@ -0,0 +1,41 @@
+package quotasense
+
+import (
+	"context"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+// ProxyResponse is an upstream response relayed by the management server.
+type ProxyResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header"`
+	Body       string              `json:"body"`
+}
+
+// APIRequest is an upstream request to send with an account's credentials.
+// The server replaces $TOKEN$ in header values with the account's access
+// token, e.g. "Authorization: Bearer $TOKEN$".
+type APIRequest struct {
+	Method string
+	URL    string
+	Header map[string]string
+	Body   string
+}
+
+// APICall sends req through the management server's api-call proxy as the
+// given account and returns the upstream response, whatever its status. It
+// is not retried.
+func (c *Client) APICall(ctx context.Context, account Account, req APIRequest) (*ProxyResponse, error) {
+	resp, err := c.api.APICall(ctx, models.ProxyRequest{
+		AuthIndex: account.AuthIndex,
+		Method:    req.Method,
+		URL:       req.URL,
+		Header:    req.Header,
+		Data:      req.Body,
+	})
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return &ProxyResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body}, nil
+}
@@ -0,0 +1,65 @@
+package quotasense
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/quaywin/quota-sense-cli/internal/api"
+)
+
+// ErrInvalidAuthFile is returned when an auth file fails local validation.
+var ErrInvalidAuthFile = errors.New("invalid auth file")
+
+// requiredAuthFields lists the fields an auth file must have, by provider.
+var requiredAuthFields = map[string][]string{
+	"gemini":     {"project_id"},
+	"gemini-cli": {"project_id"},
+	"codex":      {"access_token"},
+	"claude":     {"access_token"},
+}
+
+// Providers returns the provider types whose quota the client can read.
+func Providers() []string {
+	return api.ProviderNames()
+}
+
+// ValidateAuthFile checks that data is a JSON auth file with a provider
+// type and the fields that provider needs, and returns the provider.
+// Providers without quota support are accepted.
+func ValidateAuthFile(data []byte) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidAuthFile, err)
+	}
+
+	provider, _ := fields["type"].(string)
+	if provider == "" {
+		return "", fmt.Errorf("%w: missing \"type\"", ErrInvalidAuthFile)
+	}
+	for _, field := range requiredAuthFields[provider] {
+		if v, ok := fields[field].(string); !ok || v == "" {
+			return provider, fmt.Errorf("%w: %s auth file is missing %q", ErrInvalidAuthFile, provider, field)
+		}
+	}
+	return provider, nil
+}
+
+// UploadAuthFile validates an auth file and stores it on the server under
+// name, replacing any existing file with that name.
+func (c *Client) UploadAuthFile(ctx context.Context, name string, data []byte) error {
+	if !strings.HasSuffix(name, ".json") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: name %q must be a plain .json file name", ErrInvalidAuthFile, name)
+	}
+	if _, err := ValidateAuthFile(data); err != nil {
+		return err
+	}
+	return publicError(c.api.UploadAuthFile(ctx, name, data))
+}
+
+// DeleteAccount removes an account's auth file from the server.
+func (c *Client) DeleteAccount(ctx context.Context, account Account) error {
+	return publicError(c.api.DeleteAuthFile(ctx, account.FileName()))
+}
@@ -0,0 +1,48 @@
+package quotasense
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateAuthFile(t *testing.T) {
+	tests := []struct {
+		data     string
+		provider string
+		valid    bool
+	}{
+		{`{"type":"gemini-cli","project_id":"p1","token":{}}`, "gemini-cli", true},
+		{`{"type":"gemini-cli","email":"a@example.com"}`, "gemini-cli", false},
+		{`{"type":"codex","access_token":"t"}`, "codex", true},
+		{`{"type":"claude","access_token":""}`, "claude", false},
+		{`{"type":"qwen"}`, "qwen", true},
+		{`{"email":"a@example.com"}`, "", false},
+		{`not json`, "", false},
+	}
+
+	for _, test := range tests {
+		provider, err := ValidateAuthFile([]byte(test.data))
+		if provider != test.provider || (err == nil) != test.valid {
+			t.Errorf("ValidateAuthFile(%s) = %q, %v; want %q, valid=%v", test.data, provider, err, test.provider, test.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidAuthFile) {
+			t.Errorf("ValidateAuthFile(%s) error %v is not ErrInvalidAuthFile", test.data, err)
+		}
+	}
+}
+
+func TestUploadAuthFileRejectsBadNames(t *testing.T) {
+	server := newTestServer(t)
+	client, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	data := []byte(`{"type":"codex","access_token":"t"}`)
+	for _, name := range []string{"codex", "../codex.json", `dir\codex.json`} {
+		if err := client.UploadAuthFile(context.Background(), name, data); !errors.Is(err, ErrInvalidAuthFile) {
+			t.Errorf("UploadAuthFile(%q) = %v; want ErrInvalidAuthFile", name, err)
+		}
+	}
+}
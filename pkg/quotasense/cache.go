@@ -0,0 +1,59 @@
+package quotasense
+
+import (
+	"context"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/cache"
+)
+
+// WithResponseCache keeps each account's limits on disk in dir for maxAge.
+// Concurrent clients sharing dir, including other processes, wait for a
+// single refresh of an entry rather than each fetching it. A zero maxAge
+// disables the cache.
+func WithResponseCache(dir string, maxAge time.Duration) Option {
+	return func(o *options) {
+		o.cacheDir = dir
+		o.cacheMaxAge = maxAge
+	}
+}
+
+// cacheKey identifies an account's entry by server, provider and auth index.
+func (c *Client) cacheKey(a Account) string {
+	return c.serverURL + "\x00" + a.Provider + "\x00" + a.AuthIndex
+}
+
+// cachedQuotas returns the account's cached limits if they are fresh enough,
+// otherwise fetches and stores them while holding the entry's lock. cachedAt
+// is when cached limits were stored, and zero for fresh ones. Errors are
+// never cached.
+func (c *Client) cachedQuotas(ctx context.Context, account Account) (limits []Limit, cachedAt time.Time, err error) {
+	if c.cache == nil {
+		limits, err = c.fetchQuota(ctx, account)
+		return limits, time.Time{}, err
+	}
+
+	key := c.cacheKey(account)
+	unlock, err := c.cache.Lock(ctx, key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer unlock()
+
+	if storedAt, ok := c.cache.Get(key, c.cacheMaxAge, &limits); ok {
+		return limits, storedAt, nil
+	}
+	limits, err = c.fetchQuota(ctx, account)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	_ = c.cache.Put(key, limits)
+	return limits, time.Time{}, nil
+}
+
+func openResponseCache(dir string, maxAge time.Duration) (*cache.Store, error) {
+	if dir == "" || maxAge <= 0 {
+		return nil, nil
+	}
+	return cache.Open(dir)
+}
@@ -0,0 +1,91 @@
+package quotasense
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quaywin/quota-sense-cli/internal/api"
+)
+
+// Errors returned by the client. Use errors.Is and errors.As to inspect them.
+var (
+	// ErrUnauthorized is matched by ManagementError and UpstreamError values
+	// carrying a 401 or 403 status.
+	ErrUnauthorized = api.ErrUnauthorized
+	// ErrUnsupportedProvider is returned when the client cannot read quota
+	// for an account's provider type.
+	ErrUnsupportedProvider = api.ErrUnsupportedProvider
+)
+
+// ManagementError reports a non-200 status from the management server.
+type ManagementError struct {
+	Endpoint   string
+	StatusCode int
+	// Body is the start of the response body.
+	Body string
+}
+
+func (e *ManagementError) Error() string {
+	return fmt.Sprintf("management server returned status %d for %s", e.StatusCode, e.Endpoint)
+}
+
+func (e *ManagementError) Unwrap() error {
+	return unauthorizedFor(e.StatusCode)
+}
+
+// UpstreamError reports a non-200 status from a provider API behind the
+// management server's api-call proxy.
+type UpstreamError struct {
+	Provider   string
+	StatusCode int
+	// Body is the upstream response body.
+	Body string
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s API returned status %d", e.Provider, e.StatusCode)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return unauthorizedFor(e.StatusCode)
+}
+
+func unauthorizedFor(statusCode int) error {
+	if statusCode == 401 || statusCode == 403 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// wrappedError keeps the message and chain of an internal error while adding
+// its public equivalent for errors.As.
+type wrappedError struct {
+	err    error
+	public error
+}
+
+func (e *wrappedError) Error() string { return e.err.Error() }
+
+func (e *wrappedError) Unwrap() []error { return []error{e.public, e.err} }
+
+// publicError replaces the internal error types in err with the ones
+// exported by this package.
+func publicError(err error) error {
+	var public, internal error
+	var mgmtErr *api.ManagementError
+	var upstreamErr *api.UpstreamError
+	switch {
+	case errors.As(err, &mgmtErr):
+		public = &ManagementError{Endpoint: mgmtErr.Endpoint, StatusCode: mgmtErr.StatusCode, Body: mgmtErr.Body}
+		internal = mgmtErr
+	case errors.As(err, &upstreamErr):
+		public = &UpstreamError{Provider: upstreamErr.Provider, StatusCode: upstreamErr.StatusCode, Body: upstreamErr.Body}
+		internal = upstreamErr
+	default:
+		return err
+	}
+	if err == internal {
+		return public
+	}
+	return &wrappedError{err: err, public: public}
+}
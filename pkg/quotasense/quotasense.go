@@ -0,0 +1,322 @@
+// Package quotasense reads AI model quota from a CLIProxyAPI-compatible
+// management server. It is the library behind the qs command.
+//
+//	client, err := quotasense.New("http://localhost:8317", token)
+//	if err != nil { ... }
+//	snap, err := client.Snapshot(ctx, quotasense.Filter{Providers: []string{"codex"}})
+package quotasense
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/api"
+	"github.com/quaywin/quota-sense-cli/internal/cache"
+	"github.com/quaywin/quota-sense-cli/internal/config"
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+// DefaultConcurrency is the number of accounts Snapshot fetches in parallel.
+const DefaultConcurrency = api.DefaultConcurrency
+
+// Client fetches accounts and quota from a management server. It is safe for
+// concurrent use.
+type Client struct {
+	api         *api.Client
+	concurrency int
+	serverURL   string
+
+	cache       *cache.Store
+	cacheMaxAge time.Duration
+}
+
+// TLSConfig holds file-based TLS settings for the management server.
+type TLSConfig struct {
+	CABundle           string
+	ClientCert         string
+	ClientKey          string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// RetryPolicy controls retries of upstream 429/5xx responses. Zero fields
+// keep the defaults.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type options struct {
+	cfg     config.Config
+	apiOpts []api.Option
+
+	cacheDir    string
+	cacheMaxAge time.Duration
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+// WithManagementTimeout sets the timeout for requests to the management server.
+func WithManagementTimeout(d time.Duration) Option {
+	return func(o *options) { o.apiOpts = append(o.apiOpts, api.WithManagementTimeout(d)) }
+}
+
+// WithQuotaTimeout sets the timeout for each proxied quota request.
+func WithQuotaTimeout(d time.Duration) Option {
+	return func(o *options) { o.apiOpts = append(o.apiOpts, api.WithQuotaTimeout(d)) }
+}
+
+// WithConcurrency sets how many accounts Snapshot fetches in parallel.
+func WithConcurrency(n int) Option {
+	return func(o *options) { o.cfg.Concurrency = n }
+}
+
+// WithRetryPolicy sets the retry policy for a provider, or for all providers
+// without their own policy when provider is "default".
+func WithRetryPolicy(provider string, p RetryPolicy) Option {
+	return func(o *options) {
+		if o.cfg.Retry == nil {
+			o.cfg.Retry = make(map[string]config.RetryPolicy)
+		}
+		o.cfg.Retry[provider] = config.RetryPolicy{
+			MaxAttempts:      p.MaxAttempts,
+			InitialBackoffMs: int(p.InitialBackoff / time.Millisecond),
+			MaxBackoffMs:     int(p.MaxBackoff / time.Millisecond),
+		}
+	}
+}
+
+// WithTLS sets a CA bundle, client certificate or server name for the
+// management server connection.
+func WithTLS(tc TLSConfig) Option {
+	return func(o *options) {
+		o.cfg.TLS = &config.TLSConfig{
+			CABundle:           tc.CABundle,
+			ClientCert:         tc.ClientCert,
+			ClientKey:          tc.ClientKey,
+			ServerName:         tc.ServerName,
+			InsecureSkipVerify: tc.InsecureSkipVerify,
+		}
+	}
+}
+
+// WithProxyURL routes requests through an http, https or socks5 proxy.
+func WithProxyURL(proxyURL string) Option {
+	return func(o *options) { o.cfg.ProxyURL = proxyURL }
+}
+
+// WithLogger logs every management and proxied request at debug level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.apiOpts = append(o.apiOpts, api.WithLogger(logger)) }
+}
+
+// WithBodyDump includes redacted request and response bodies in debug logs.
+func WithBodyDump(enabled bool) Option {
+	return func(o *options) { o.apiOpts = append(o.apiOpts, api.WithBodyDump(enabled)) }
+}
+
+// WithUnsafeLogging disables redaction of secrets in debug logs.
+func WithUnsafeLogging(enabled bool) Option {
+	return func(o *options) { o.apiOpts = append(o.apiOpts, api.WithUnsafeLogging(enabled)) }
+}
+
+// New creates a client for the management server at serverURL, which may be
+// an http(s) URL or a unix:// socket URL.
+func New(serverURL, managementToken string, opts ...Option) (*Client, error) {
+	o := options{cfg: config.Config{
+		ServerURL:       strings.TrimSuffix(serverURL, "/"),
+		ManagementToken: managementToken,
+	}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.cfg.ServerURL == "" || o.cfg.ManagementToken == "" {
+		return nil, fmt.Errorf("server URL and management token are required")
+	}
+	if err := config.ValidateServerURL(o.cfg.ServerURL); err != nil {
+		return nil, err
+	}
+
+	apiClient, err := api.NewClient(&o.cfg, o.apiOpts...)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := o.cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	store, err := openResponseCache(o.cacheDir, o.cacheMaxAge)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		api:         apiClient,
+		concurrency: concurrency,
+		serverURL:   o.cfg.ServerURL,
+		cache:       store,
+		cacheMaxAge: o.cacheMaxAge,
+	}, nil
+}
+
+// CheckConnection verifies that the server is reachable and the token valid.
+func (c *Client) CheckConnection(ctx context.Context) error {
+	return publicError(c.api.CheckConnection(ctx))
+}
+
+// ListAccounts returns every auth file on the server without contacting any
+// upstream provider.
+func (c *Client) ListAccounts(ctx context.Context) ([]Account, error) {
+	files, err := c.api.FetchUsage(ctx)
+	if err != nil {
+		return nil, publicError(err)
+	}
+	accounts := make([]Account, len(files))
+	for i, f := range files {
+		accounts[i] = accountFromFile(f)
+	}
+	return accounts, nil
+}
+
+// SetDisabled enables or disables an account on the management server.
+// Disabled accounts are skipped by the proxy until they are enabled again.
+func (c *Client) SetDisabled(ctx context.Context, account Account, disabled bool) error {
+	return publicError(c.api.SetAuthFileDisabled(ctx, account.FileName(), disabled))
+}
+
+// Quotas fetches the current limits for one account, or returns them from
+// the response cache if one is configured and the entry is fresh.
+func (c *Client) Quotas(ctx context.Context, account Account) ([]Limit, error) {
+	limits, _, err := c.cachedQuotas(ctx, account)
+	return limits, err
+}
+
+// fetchQuota fetches an account's limits from its provider.
+func (c *Client) fetchQuota(ctx context.Context, account Account) ([]Limit, error) {
+	limits, err := c.api.FetchQuota(ctx, account.authFile())
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return limitsFromModels(limits), nil
+}
+
+// Snapshot lists the accounts matching filter and fetches their quota in
+// parallel. Per-account failures are reported in AccountQuota.Err. If ctx is
+// cancelled, accounts that did not finish have Err set to ctx.Err().
+func (c *Client) Snapshot(ctx context.Context, filter Filter) (*Snapshot, error) {
+	accounts, err := c.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []Account
+	for _, a := range accounts {
+		if filter.Match(a) {
+			selected = append(selected, a)
+		}
+	}
+
+	snap := &Snapshot{
+		FetchedAt: time.Now(),
+		Accounts:  make([]AccountQuota, len(selected)),
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	workers := min(c.concurrency, len(selected))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				snap.Accounts[idx] = c.fetchAccount(ctx, selected[idx])
+			}
+		}()
+	}
+	for i := range selected {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return snap, nil
+}
+
+func (c *Client) fetchAccount(ctx context.Context, a Account) AccountQuota {
+	res := AccountQuota{Account: a}
+	if ctx.Err() != nil {
+		res.Err = ctx.Err()
+		return res
+	}
+
+	start := time.Now()
+	var cachedAt time.Time
+	res.Limits, cachedAt, res.Err = c.cachedQuotas(ctx, a)
+	res.Latency = time.Since(start)
+	res.FetchedAt = time.Now()
+	if !cachedAt.IsZero() {
+		res.Cached = true
+		res.FetchedAt = cachedAt
+	}
+	return res
+}
+
+// Match reports whether the filter selects a.
+func (f Filter) Match(a Account) bool {
+	if f.ExcludeDisabled && a.Disabled {
+		return false
+	}
+	if len(f.Providers) > 0 && !slices.Contains(f.Providers, a.Provider) {
+		return false
+	}
+	if len(f.Accounts) == 0 {
+		return true
+	}
+	for _, pattern := range f.Accounts {
+		for _, candidate := range []string{a.Email, a.ID, a.Name} {
+			if candidate == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, candidate); ok || pattern == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func accountFromFile(f models.AuthFile) Account {
+	return Account{
+		ID:               f.ID,
+		Name:             f.Name,
+		Email:            f.Email,
+		Provider:         f.Provider,
+		Disabled:         f.Disabled,
+		AuthIndex:        f.AuthIndex,
+		ProjectID:        f.ProjectID,
+		Label:            f.Account,
+		ChatGPTAccountID: f.IDToken.ChatgptAccountID,
+		Metadata:         f.Raw,
+	}
+}
+
+func (a Account) authFile() models.AuthFile {
+	return models.AuthFile{
+		ID:        a.ID,
+		Name:      a.Name,
+		Email:     a.Email,
+		Provider:  a.Provider,
+		Disabled:  a.Disabled,
+		AuthIndex: a.AuthIndex,
+		ProjectID: a.ProjectID,
+		Account:   a.Label,
+		IDToken:   models.IDToken{ChatgptAccountID: a.ChatGPTAccountID},
+	}
+}
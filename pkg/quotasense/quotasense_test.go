@@ -0,0 +1,264 @@
+package quotasense
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/management/auth-files", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"files":[
+			{"id":"g.json","email":"gem@example.com","type":"gemini-cli","auth_index":"1","project_id":"p1"},
+			{"id":"c.json","email":"codex@example.com","type":"codex","auth_index":"2","disabled":true},
+			{"id":"q.json","email":"qwen@example.com","type":"qwen","auth_index":"3"}
+		]}`))
+	})
+	mux.HandleFunc("/v0/management/api-call", func(w http.ResponseWriter, r *http.Request) {
+		var req models.ProxyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var resp models.ProxyResponse
+		switch req.AuthIndex {
+		case "1":
+			resp = models.ProxyResponse{StatusCode: 200, Body: `{"buckets":[
+				{"modelId":"gemini-2.5-pro","remainingFraction":0.25,"resetTime":"2030-01-01T00:00:00Z"},
+				{"modelId":"gemini-2.5-flash","remainingFraction":1}
+			]}`}
+		default:
+			resp = models.ProxyResponse{StatusCode: 401, Body: `{"error":"expired"}`}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSnapshot(t *testing.T) {
+	server := newTestServer(t)
+	client, err := New(server.URL, "token", WithConcurrency(2), WithRetryPolicy("default", RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	snap, err := client.Snapshot(context.Background(), Filter{})
+	if err != nil {
+		t.Fatalf("Snapshot returned error: %v", err)
+	}
+	if len(snap.Accounts) != 3 {
+		t.Fatalf("Snapshot returned %d accounts; want 3", len(snap.Accounts))
+	}
+
+	gem := snap.Accounts[0]
+	if gem.Err != nil || len(gem.Limits) != 2 {
+		t.Fatalf("gemini account = %+v", gem)
+	}
+	flash, pro := gem.Limits[0], gem.Limits[1]
+	if flash.ModelID != "gemini-2.5-flash" || flash.Group != "Gemini Flash" || flash.RemainingFraction != 1 || !flash.ResetAt.IsZero() {
+		t.Errorf("flash limit = %+v", flash)
+	}
+	if pro.Group != "Gemini Pro" || pro.RemainingFraction != 0.25 || !pro.ResetAt.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("pro limit = %+v", pro)
+	}
+
+	codex := snap.Accounts[1]
+	var upstreamErr *UpstreamError
+	if !errors.Is(codex.Err, ErrUnauthorized) || !errors.As(codex.Err, &upstreamErr) || upstreamErr.Provider != "codex" {
+		t.Errorf("codex error = %v; want unauthorized upstream error", codex.Err)
+	}
+
+	if !errors.Is(snap.Accounts[2].Err, ErrUnsupportedProvider) {
+		t.Errorf("qwen error = %v; want ErrUnsupportedProvider", snap.Accounts[2].Err)
+	}
+}
+
+func TestSnapshotFilter(t *testing.T) {
+	server := newTestServer(t)
+	client, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	tests := []struct {
+		filter   Filter
+		expected []string
+	}{
+		{Filter{Providers: []string{"gemini-cli", "codex"}}, []string{"g.json", "c.json"}},
+		{Filter{Accounts: []string{"*@example.com"}, ExcludeDisabled: true}, []string{"g.json", "q.json"}},
+		{Filter{Accounts: []string{"c.json"}}, []string{"c.json"}},
+	}
+
+	for _, test := range tests {
+		snap, err := client.Snapshot(context.Background(), test.filter)
+		if err != nil {
+			t.Fatalf("Snapshot(%+v) returned error: %v", test.filter, err)
+		}
+		var ids []string
+		for _, a := range snap.Accounts {
+			ids = append(ids, a.Account.ID)
+		}
+		if len(ids) != len(test.expected) {
+			t.Errorf("Snapshot(%+v) accounts = %v; want %v", test.filter, ids, test.expected)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != test.expected[i] {
+				t.Errorf("Snapshot(%+v) accounts = %v; want %v", test.filter, ids, test.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestNewValidatesServerURL(t *testing.T) {
+	if _, err := New("localhost:8080", "token"); err == nil {
+		t.Errorf("New accepted a server URL without a scheme")
+	}
+	if _, err := New("http://localhost:8080", ""); err == nil {
+		t.Errorf("New accepted an empty management token")
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	server := newTestServer(t)
+	var calls atomic.Int32
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0/management/api-call" {
+			calls.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	dir := t.TempDir()
+	filter := Filter{Providers: []string{"gemini-cli"}}
+	var firstFetch time.Time
+	for i, wantCached := range []bool{false, true} {
+		client, err := New(server.URL, "token", WithResponseCache(dir, time.Minute))
+		if err != nil {
+			t.Fatalf("New returned error: %v", err)
+		}
+		snap, err := client.Snapshot(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("Snapshot returned error: %v", err)
+		}
+		res := snap.Accounts[0]
+		if res.Err != nil || len(res.Limits) != 2 || res.Cached != wantCached {
+			t.Errorf("run %d: account = %+v; want cached=%v", i, res, wantCached)
+		}
+		if i == 0 {
+			firstFetch = res.FetchedAt
+			time.Sleep(10 * time.Millisecond)
+		} else if res.FetchedAt.After(firstFetch) {
+			t.Errorf("cached account FetchedAt = %v; want the original fetch at %v or before", res.FetchedAt, firstFetch)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream was called %d times; want 1", n)
+	}
+}
+
+func TestSetDisabledAndDeleteUseFileName(t *testing.T) {
+	var status models.AuthFileStatusRequest
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v0/management/auth-files":
+			w.Write([]byte(`{"files":[
+				{"id":"codex-user@example.com","name":"c.json","email":"user@example.com","type":"codex"},
+				{"id":"g.json","email":"gem@example.com","type":"gemini-cli"}
+			]}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/v0/management/auth-files/status":
+			json.NewDecoder(r.Body).Decode(&status)
+			w.Write([]byte(`{"status":"ok"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v0/management/auth-files":
+			deleted = append(deleted, r.URL.Query().Get("name"))
+			w.Write([]byte(`{"status":"ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	accounts, err := client.ListAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("ListAccounts returned error: %v", err)
+	}
+	codex, gem := accounts[0], accounts[1]
+	if codex.Name != "c.json" || codex.FileName() != "c.json" || gem.FileName() != "g.json" {
+		t.Fatalf("file names = %q, %q; want c.json and the g.json ID fallback", codex.FileName(), gem.FileName())
+	}
+
+	if err := client.SetDisabled(context.Background(), codex, true); err != nil {
+		t.Fatalf("SetDisabled returned error: %v", err)
+	}
+	if status.Name != "c.json" || !status.Disabled {
+		t.Errorf("status request = %+v; want c.json disabled", status)
+	}
+	for _, a := range accounts {
+		if err := client.DeleteAccount(context.Background(), a); err != nil {
+			t.Fatalf("DeleteAccount returned error: %v", err)
+		}
+	}
+	if len(deleted) != 2 || deleted[0] != "c.json" || deleted[1] != "g.json" {
+		t.Errorf("deleted %v; want [c.json g.json]", deleted)
+	}
+}
+
+func TestCheckConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"bad token"}`))
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL, "token")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	err = client.CheckConnection(context.Background())
+	var mgmtErr *ManagementError
+	if !errors.Is(err, ErrUnauthorized) || !errors.As(err, &mgmtErr) || mgmtErr.StatusCode != 401 {
+		t.Errorf("CheckConnection error = %v; want an unauthorized *ManagementError", err)
+	}
+}
+
+func TestSubSecondTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := New(server.URL, "token", WithManagementTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	start := time.Now()
+	if _, err := client.ListAccounts(context.Background()); err == nil {
+		t.Fatal("ListAccounts succeeded against a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("ListAccounts took %v; want the 100ms timeout to apply", elapsed)
+	}
+}
@@ -0,0 +1,143 @@
+package quotasense
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/quaywin/quota-sense-cli/internal/models"
+)
+
+// Account is an auth file known to the management server.
+type Account struct {
+	ID string `json:"id"`
+	// Name is the auth file's name, which the management server addresses
+	// it by when enabling, disabling, replacing or deleting it. It is
+	// usually, but not always, the same as ID.
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email"`
+	Provider  string `json:"provider"`
+	Disabled  bool   `json:"disabled"`
+	AuthIndex string `json:"auth_index"`
+	ProjectID string `json:"project_id,omitempty"`
+	// Label is the server's free-form account description, which often
+	// includes the project ID in parentheses.
+	Label string `json:"label,omitempty"`
+	// ChatGPTAccountID is set for codex accounts.
+	ChatGPTAccountID string `json:"chatgpt_account_id,omitempty"`
+	// Metadata is the server's auth file entry as returned, including
+	// fields the client does not model.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// FileName returns the name the management server knows the account's auth
+// file by, falling back to ID for servers that do not report one.
+func (a Account) FileName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.ID
+}
+
+// Limit is one quota window or model limit for an account, with typed
+// fractions, reset times and window lengths.
+type Limit struct {
+	// ModelID is the model or window identifier as reported by the provider.
+	ModelID string `json:"model_id"`
+	// Provider is the auth file type the limit was fetched for.
+	Provider string `json:"provider"`
+	// DisplayName is the provider's human-readable name, if any.
+	DisplayName string `json:"display_name,omitempty"`
+	// Group is the name the model is grouped under in summary views. It is
+	// empty for models that are only shown when all models are requested.
+	Group string `json:"group,omitempty"`
+	// RemainingFraction is the remaining quota between 0 and 1.
+	RemainingFraction float64 `json:"remaining_fraction"`
+	// QuotaUnknown is set when the provider lists the model without any
+	// quota information; RemainingFraction is then meaningless.
+	QuotaUnknown bool `json:"quota_unknown,omitempty"`
+	// ResetAt is when the quota resets; zero if unknown.
+	ResetAt time.Time `json:"reset_at,omitzero"`
+	// Window is the length of the rate-limit window; zero if unknown.
+	Window time.Duration `json:"window,omitempty"`
+	// Blocked is set when the provider reports the limit as reached,
+	// regardless of the remaining fraction.
+	Blocked bool `json:"blocked,omitempty"`
+	// Credits is set for entries that report a credit balance rather than
+	// a percentage.
+	Credits *Credits `json:"credits,omitempty"`
+	// FetchedAt is when the limit was read from the provider.
+	FetchedAt time.Time `json:"fetched_at"`
+	// Metadata holds any other per-model details the provider reports,
+	// such as token limits or capabilities.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Credits is a prepaid credit balance reported in place of a percentage.
+type Credits struct {
+	Unlimited  bool     `json:"unlimited,omitempty"`
+	HasCredits bool     `json:"has_credits,omitempty"`
+	Balance    *float64 `json:"balance,omitempty"`
+}
+
+func limitFromModel(m models.ModelLimit) Limit {
+	l := Limit{
+		ModelID:           m.ModelID,
+		Provider:          m.Provider,
+		DisplayName:       m.DisplayName,
+		Group:             m.Group,
+		RemainingFraction: m.RemainingFraction,
+		QuotaUnknown:      m.QuotaUnknown,
+		ResetAt:           m.ResetAt,
+		Window:            m.Window,
+		Blocked:           m.Blocked,
+		FetchedAt:         m.FetchedAt,
+		Metadata:          m.Metadata,
+	}
+	if m.Credits != nil {
+		l.Credits = &Credits{Unlimited: m.Credits.Unlimited, HasCredits: m.Credits.HasCredits, Balance: m.Credits.Balance}
+	}
+	return l
+}
+
+func limitsFromModels(ms []models.ModelLimit) []Limit {
+	if ms == nil {
+		return nil
+	}
+	limits := make([]Limit, len(ms))
+	for i, m := range ms {
+		limits[i] = limitFromModel(m)
+	}
+	return limits
+}
+
+// AccountQuota is the result of fetching quota for one account.
+type AccountQuota struct {
+	Account Account
+	Limits  []Limit
+	// Err is set when the quota could not be fetched; Limits is then empty.
+	Err     error
+	Latency time.Duration
+	// FetchedAt is when Limits were fetched from the provider, which for
+	// cached limits is when they were stored.
+	FetchedAt time.Time
+	// Cached is true when Limits came from the response cache.
+	Cached bool
+}
+
+// Filter selects accounts for Snapshot. Zero values match everything.
+type Filter struct {
+	// Providers limits the snapshot to these provider types.
+	Providers []string
+	// Accounts matches account emails or IDs, with shell-style globs.
+	Accounts []string
+	// ExcludeDisabled skips accounts disabled on the server.
+	ExcludeDisabled bool
+}
+
+// Snapshot is the quota of every selected account at a point in time.
+type Snapshot struct {
+	// FetchedAt is when the snapshot was taken. Cached accounts can be
+	// older; see AccountQuota.FetchedAt.
+	FetchedAt time.Time
+	Accounts  []AccountQuota
+}
@@ -0,0 +1,69 @@
+package quotasense
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// UsageRecord is one request proxied by the server, from its usage
+// statistics.
+type UsageRecord struct {
+	Time time.Time `json:"time"`
+	// APIKey is the client API key the request was made with.
+	APIKey string `json:"api_key"`
+	Model  string `json:"model"`
+	// Source identifies the account that served the request, usually by
+	// email. AuthIndex is set when the server reports it.
+	Source    string `json:"source,omitempty"`
+	AuthIndex string `json:"auth_index,omitempty"`
+
+	InputTokens     int64 `json:"input_tokens"`
+	OutputTokens    int64 `json:"output_tokens"`
+	ReasoningTokens int64 `json:"reasoning_tokens,omitempty"`
+	CachedTokens    int64 `json:"cached_tokens,omitempty"`
+	TotalTokens     int64 `json:"total_tokens"`
+	Failed          bool  `json:"failed,omitempty"`
+}
+
+// UsageRecords returns every request in the server's usage statistics,
+// oldest first. The server keeps these in memory, so they only cover the
+// time since it last started.
+func (c *Client) UsageRecords(ctx context.Context) ([]UsageRecord, error) {
+	stats, err := c.api.FetchUsageStatistics(ctx)
+	if err != nil {
+		return nil, publicError(err)
+	}
+
+	var records []UsageRecord
+	for apiKey, api := range stats.Usage.APIs {
+		for model, m := range api.Models {
+			for _, d := range m.Details {
+				records = append(records, UsageRecord{
+					Time:            d.Timestamp,
+					APIKey:          apiKey,
+					Model:           model,
+					Source:          d.Source,
+					AuthIndex:       d.AuthIndex,
+					InputTokens:     d.Tokens.InputTokens,
+					OutputTokens:    d.Tokens.OutputTokens,
+					ReasoningTokens: d.Tokens.ReasoningTokens,
+					CachedTokens:    d.Tokens.CachedTokens,
+					TotalTokens:     d.Tokens.TotalTokens,
+					Failed:          d.Failed,
+				})
+			}
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.APIKey != b.APIKey {
+			return a.APIKey < b.APIKey
+		}
+		return a.Model < b.Model
+	})
+	return records, nil
+}
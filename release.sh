@@ -42,6 +42,26 @@ fi
 BINARY_NAME="qs"
 DIST_DIR="dist"
 
+# Release checksums are signed with minisign; qs update refuses unsigned
+# releases. The binaries only trust the public key committed as
+# cmd/keys/minisign.pub, so the secret key must match it.
+MINISIGN_PUBLIC_KEY_FILE=cmd/keys/minisign.pub
+MINISIGN_SECRET_KEY_FILE=${MINISIGN_SECRET_KEY_FILE:-$HOME/.minisign/minisign.key}
+
+if ! command -v minisign > /dev/null; then
+    echo "Error: minisign is required to sign releases."
+    exit 1
+fi
+if [ ! -f "$MINISIGN_PUBLIC_KEY_FILE" ]; then
+    echo "Error: no trusted key configured; commit the release public key as $MINISIGN_PUBLIC_KEY_FILE first."
+    exit 1
+fi
+if [ ! -f "$MINISIGN_SECRET_KEY_FILE" ]; then
+    echo "Error: minisign secret key not found ($MINISIGN_SECRET_KEY_FILE)."
+    exit 1
+fi
+LDFLAGS="-X github.com/quaywin/quota-sense-cli/cmd.Version=$VERSION"
+
 echo "🚀 Preparing release $VERSION..."
 
 # Clean dist directory
@@ -68,7 +88,7 @@ for platform in "${platforms[@]}"; do
     fi
 
     echo "📦 Building for $GOOS/$GOARCH..."
-    env GOOS=$GOOS GOARCH=$GOARCH go build -ldflags "-X github.com/quaywin/quota-sense-cli/cmd.Version=$VERSION" -o "$DIST_DIR/$OUTPUT_NAME" main.go
+    env GOOS=$GOOS GOARCH=$GOARCH go build -ldflags "$LDFLAGS" -o "$DIST_DIR/$OUTPUT_NAME" main.go
 
     # Package
     PACKAGE_NAME="${BINARY_NAME}_${VERSION}_${GOOS}_${GOARCH}"
@@ -81,6 +101,19 @@ for platform in "${platforms[@]}"; do
     rm "$DIST_DIR/$OUTPUT_NAME"
 done
 
+echo "🔏 Signing checksums..."
+(
+    cd $DIST_DIR || exit 1
+    if command -v sha256sum > /dev/null; then
+        sha256sum *.tar.gz *.zip > SHA256SUMS
+    else
+        shasum -a 256 *.tar.gz *.zip > SHA256SUMS
+    fi
+) || exit 1
+minisign -S -s "$MINISIGN_SECRET_KEY_FILE" -m "$DIST_DIR/SHA256SUMS" -t "quota-sense-cli $VERSION" || exit 1
+# Make sure the secret key matches the key the binaries trust.
+minisign -V -q -p "$MINISIGN_PUBLIC_KEY_FILE" -m "$DIST_DIR/SHA256SUMS" || exit 1
+
 echo "📝 Generating changelog..."
 if [ -z "$NOTES" ]; then
     # Get changes since last tag
@@ -93,6 +126,6 @@ if [ -z "$NOTES" ]; then
 fi
 
 echo "📤 Uploading to GitHub..."
-gh release create "$VERSION" $DIST_DIR/*.tar.gz $DIST_DIR/*.zip --title "Release $VERSION" --notes "$NOTES"
+gh release create "$VERSION" $DIST_DIR/*.tar.gz $DIST_DIR/*.zip $DIST_DIR/SHA256SUMS $DIST_DIR/SHA256SUMS.minisig --title "Release $VERSION" --notes "$NOTES"
 
 echo "✅ Release $VERSION completed successfully!"